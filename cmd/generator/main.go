@@ -1,27 +1,11 @@
 package main
 
 import (
-	"log"
 	"os"
 
-	"github.com/hedhyw/rex/internal/generator"
+	"github.com/hedhyw/rex/internal/cli"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		log.Fatalln("wrong amount of arguments")
-	}
-
-	if len(os.Args[1]) == 0 {
-		log.Fatalln("given regex is empty")
-	}
-
-	regex := os.Args[1]
-
-	result, err := generator.GenerateCode(regex)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	os.Stdout.WriteString(result + "\n")
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
 }
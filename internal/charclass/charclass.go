@@ -0,0 +1,188 @@
+// Package charclass contains operations on sets of runes. A set is
+// represented as pairs of runes [lo, hi] like in regexp/syntax.
+package charclass
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Predefined ASCII classes.
+var (
+	// Digits is `\d`.
+	Digits = []rune{'0', '9'}
+	// Whitespace is `\s`.
+	Whitespace = []rune{'\t', '\n', '\f', '\r', ' ', ' '}
+	// Word is `\w`.
+	Word = []rune{'0', '9', 'A', 'Z', '_', '_', 'a', 'z'}
+)
+
+// POSIX contains ASCII classes by their names: `[:alpha:]`.
+var POSIX = map[string][]rune{
+	"alnum":  {'0', '9', 'A', 'Z', 'a', 'z'},
+	"alpha":  {'A', 'Z', 'a', 'z'},
+	"ascii":  {0x00, 0x7F},
+	"blank":  {'\t', '\t', ' ', ' '},
+	"cntrl":  {0x00, 0x1F, 0x7F, 0x7F},
+	"digit":  {'0', '9'},
+	"graph":  {'!', '~'},
+	"lower":  {'a', 'z'},
+	"print":  {' ', '~'},
+	"punct":  {'!', '/', ':', '@', '[', '`', '{', '~'},
+	"space":  {'\t', '\r', ' ', ' '},
+	"upper":  {'A', 'Z'},
+	"word":   {'0', '9', 'A', 'Z', '_', '_', 'a', 'z'},
+	"xdigit": {'0', '9', 'A', 'F', 'a', 'f'},
+}
+
+// FromTable converts the unicode table to pairs of runes.
+func FromTable(table *unicode.RangeTable) []rune {
+	if table == nil {
+		return nil
+	}
+
+	ranges := make([]rune, 0, 2*(len(table.R16)+len(table.R32)))
+
+	for _, r := range table.R16 {
+		ranges = appendRange(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	for _, r := range table.R32 {
+		ranges = appendRange(ranges, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	return Normalize(ranges)
+}
+
+func appendRange(ranges []rune, lo, hi, stride rune) []rune {
+	if stride <= 1 {
+		return append(ranges, lo, hi)
+	}
+
+	for r := lo; r <= hi; r += stride {
+		ranges = append(ranges, r, r)
+	}
+
+	return ranges
+}
+
+// Normalize sorts and merges overlapping and adjacent pairs.
+// It returns a new slice.
+func Normalize(ranges []rune) []rune {
+	pairs := make([][2]rune, 0, len(ranges)/2)
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		pairs = append(pairs, [2]rune{ranges[i], ranges[i+1]})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+
+	normalized := make([]rune, 0, len(ranges))
+
+	for _, p := range pairs {
+		last := len(normalized) - 1
+		if last > 0 && p[0] <= normalized[last]+1 {
+			if p[1] > normalized[last] {
+				normalized[last] = p[1]
+			}
+
+			continue
+		}
+
+		normalized = append(normalized, p[0], p[1])
+	}
+
+	return normalized
+}
+
+// Union returns all runes from given sets.
+func Union(sets ...[]rune) []rune {
+	var size int
+
+	for _, set := range sets {
+		size += len(set)
+	}
+
+	ranges := make([]rune, 0, size)
+
+	for _, set := range sets {
+		ranges = append(ranges, set...)
+	}
+
+	return Normalize(ranges)
+}
+
+// Negate returns all runes that are not in the set.
+func Negate(ranges []rune) []rune {
+	ranges = Normalize(ranges)
+	negated := make([]rune, 0, len(ranges)+2)
+
+	next := rune(0)
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] > next {
+			negated = append(negated, next, ranges[i]-1)
+		}
+
+		next = ranges[i+1] + 1
+	}
+
+	if next <= unicode.MaxRune {
+		negated = append(negated, next, unicode.MaxRune)
+	}
+
+	return negated
+}
+
+// Minimum and maximum runes that have case folding.
+const (
+	minFold = 0x0041
+	maxFold = 0x1E943
+)
+
+// FoldCase adds all case variants of runes, as the flag `(?i)` does.
+func FoldCase(ranges []rune) []rune {
+	folded := append([]rune(nil), ranges...)
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+
+		if lo < minFold {
+			lo = minFold
+		}
+
+		if hi > maxFold {
+			hi = maxFold
+		}
+
+		for r := lo; r <= hi; r++ {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				folded = append(folded, f, f)
+			}
+		}
+	}
+
+	return Normalize(folded)
+}
+
+// Intersect returns runes that are in all sets.
+func Intersect(sets ...[]rune) []rune {
+	if len(sets) == 0 {
+		return nil
+	}
+
+	negated := make([][]rune, 0, len(sets))
+
+	for _, set := range sets {
+		negated = append(negated, Negate(set))
+	}
+
+	return Negate(Union(negated...))
+}
+
+// Subtract returns runes from the set that are not in others.
+func Subtract(set []rune, others ...[]rune) []rune {
+	return Intersect(set, Negate(Union(others...)))
+}
@@ -0,0 +1,201 @@
+package charclass_test
+
+import (
+	"reflect"
+	"testing"
+	"unicode"
+
+	"github.com/hedhyw/rex/internal/charclass"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Value    []rune
+		Expected []rune
+	}{{
+		Name:     "empty",
+		Value:    []rune{},
+		Expected: []rune{},
+	}, {
+		Name:     "sorted",
+		Value:    []rune{'x', 'z', 'a', 'c'},
+		Expected: []rune{'a', 'c', 'x', 'z'},
+	}, {
+		Name:     "overlapping",
+		Value:    []rune{'a', 'f', 'c', 'z'},
+		Expected: []rune{'a', 'z'},
+	}, {
+		Name:     "adjacent",
+		Value:    []rune{'a', 'c', 'd', 'f'},
+		Expected: []rune{'a', 'f'},
+	}, {
+		Name:     "contained",
+		Value:    []rune{'a', 'z', 'c', 'd'},
+		Expected: []rune{'a', 'z'},
+	}, {
+		Name:     "duplicates",
+		Value:    []rune{'a', 'a', 'a', 'a', 'b', 'b'},
+		Expected: []rune{'a', 'b'},
+	}, {
+		Name:     "zero",
+		Value:    []rune{1, 2, 0, 0},
+		Expected: []rune{0, 2},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := charclass.Normalize(tc.Value)
+			if !reflect.DeepEqual(actual, tc.Expected) {
+				t.Fatalf("Actual: %q, Expected: %q", actual, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	t.Parallel()
+
+	actual := charclass.Union(charclass.Digits, charclass.POSIX["xdigit"])
+	expected := []rune{'0', '9', 'A', 'F', 'a', 'f'}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	t.Parallel()
+
+	actual := charclass.Intersect(charclass.POSIX["alnum"], charclass.POSIX["xdigit"], []rune{'5', 'B'})
+	expected := []rune{'5', '9', 'A', 'B'}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+
+	if actual := charclass.Intersect(); actual != nil {
+		t.Fatalf("Actual: %q, Expected: nil", actual)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	t.Parallel()
+
+	actual := charclass.Subtract(charclass.POSIX["lower"], []rune{'a', 'a'}, []rune{'e', 'e', 'x', 'z'})
+	expected := []rune{'b', 'd', 'f', 'w'}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+}
+
+func TestFromTable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stride", func(t *testing.T) {
+		t.Parallel()
+
+		table := &unicode.RangeTable{
+			R16: []unicode.Range16{
+				{Lo: 'a', Hi: 'e', Stride: 2},
+				{Lo: 'x', Hi: 'z', Stride: 1},
+			},
+			R32:         []unicode.Range32{{Lo: 0x10000, Hi: 0x10002, Stride: 1}},
+			LatinOffset: 0,
+		}
+
+		actual := charclass.FromTable(table)
+		expected := []rune{'a', 'a', 'c', 'c', 'e', 'e', 'x', 'z', 0x10000, 0x10002}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if actual := charclass.FromTable(nil); actual != nil {
+			t.Fatalf("Actual: %q, Expected: nil", actual)
+		}
+	})
+
+	t.Run("unicode", func(t *testing.T) {
+		t.Parallel()
+
+		ranges := charclass.FromTable(unicode.Greek)
+
+		for r := rune(0); r <= unicode.MaxRune; r++ {
+			if contains(ranges, r) != unicode.Is(unicode.Greek, r) {
+				t.Fatalf("Mismatch: %U", r)
+			}
+		}
+	})
+}
+
+func contains(ranges []rune, r rune) bool {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] <= r && r <= ranges[i+1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestNegate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Value    []rune
+		Expected []rune
+	}{{
+		Name:     "empty",
+		Value:    []rune{},
+		Expected: []rune{0, unicode.MaxRune},
+	}, {
+		Name:     "all",
+		Value:    []rune{0, unicode.MaxRune},
+		Expected: []rune{},
+	}, {
+		Name:     "middle",
+		Value:    []rune{'b', 'c', 'x', 'y'},
+		Expected: []rune{0, 'a', 'd', 'w', 'z', unicode.MaxRune},
+	}, {
+		Name:     "zero",
+		Value:    []rune{0, 'a'},
+		Expected: []rune{'b', unicode.MaxRune},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := charclass.Negate(tc.Value)
+			if !reflect.DeepEqual(actual, tc.Expected) {
+				t.Fatalf("Actual: %q, Expected: %q", actual, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	t.Parallel()
+
+	actual := charclass.FoldCase([]rune{'0', '9', 'a', 'c', 'k', 'k'})
+	expected := []rune{'0', '9', 'A', 'C', 'K', 'K', 'a', 'c', 'k', 'k', 0x212A, 0x212A}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+}
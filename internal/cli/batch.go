@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Input formats of the batch mode.
+const (
+	inputLines = "lines"
+	inputJSON  = "json"
+)
+
+// pattern is a regex with an optional name of the variable.
+type pattern struct {
+	Name    string `json:"name,omitempty"`
+	Pattern string `json:"pattern"`
+}
+
+// UnmarshalJSON accepts a string or an object.
+func (p *pattern) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &p.Pattern); err == nil {
+		return nil
+	}
+
+	type plain pattern
+
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// readPatterns reads patterns one per line, or a JSON array of strings
+// or objects: `[{"name": "emailRegExp", "pattern": "..."}]`. Empty
+// lines are skipped.
+func readPatterns(r io.Reader, format string) ([]pattern, error) {
+	switch format {
+	case inputLines:
+		var patterns []pattern
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSuffix(scanner.Text(), "\r")
+			if line != "" {
+				patterns = append(patterns, pattern{Name: "", Pattern: line})
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		return patterns, nil
+	case inputJSON:
+		var patterns []pattern
+
+		if err := json.NewDecoder(r).Decode(&patterns); err != nil {
+			return nil, fmt.Errorf("reading input: %w", err)
+		}
+
+		return patterns, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown input format %q", errUsage, format)
+	}
+}
+
+// result is written in the JSON format.
+type result struct {
+	Name    string `json:"name,omitempty"`
+	Pattern string `json:"pattern"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// resultWriter writes results of the command.
+type resultWriter struct {
+	cmd    command
+	format string
+	batch  bool
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (w resultWriter) write(p pattern, output string, err error) {
+	if w.format == formatJSON {
+		res := result{Name: p.Name, Pattern: p.Pattern, Output: output, Error: ""}
+		if err != nil {
+			res.Error = err.Error()
+		}
+
+		// Results contain only strings, so they are always encoded.
+		_ = json.NewEncoder(w.stdout).Encode(res)
+
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(w.stderr, "rex %s: %s: %s\n", w.cmd.name, p.Pattern, err)
+
+		return
+	}
+
+	output = strings.TrimSuffix(output, "\n")
+
+	if w.batch && w.cmd.showPattern {
+		output += "\t" + p.Pattern
+	}
+
+	if !w.batch || w.cmd.lineOutput {
+		fmt.Fprintln(w.stdout, output)
+
+		return
+	}
+
+	fmt.Fprintf(w.stdout, "// %s\n%s\n\n", p.Pattern, output)
+}
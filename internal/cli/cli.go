@@ -0,0 +1,317 @@
+// Package cli implements the command line interface of the generator.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/generator"
+)
+
+// Exit codes of Run.
+const (
+	// ExitOK means that all patterns are processed.
+	ExitOK = 0
+	// ExitFailure means that some patterns are failed.
+	ExitFailure = 1
+	// ExitUsage means that arguments are invalid.
+	ExitUsage = 2
+)
+
+// Output formats.
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// errUsage is returned if arguments are invalid.
+var errUsage = errors.New("usage")
+
+// command processes one pattern.
+type command struct {
+	name        string
+	description string
+	// lineOutput is set if the result is a single line. In the batch
+	// mode such results are written one per line, others are separated
+	// by headers.
+	lineOutput bool
+	// showPattern is set if results are followed by patterns in the
+	// batch mode.
+	showPattern bool
+
+	// flags registers command specific flags.
+	flags func(fs *flag.FlagSet, cfg *config)
+	// validate checks command specific flags.
+	validate func(cfg *config) error
+	// batch processes all patterns in the batch mode with the text
+	// format, if it is set. It returns the exit code.
+	batch func(cfg *config, patterns []pattern, w resultWriter) int
+	// run returns the result for the pattern.
+	run func(cfg *config, p pattern) (string, error)
+}
+
+func commands() []command {
+	return []command{
+		generateCommand(),
+		explainCommand(),
+		testCommand(),
+		convertCommand(),
+	}
+}
+
+// config contains values of flags.
+type config struct {
+	syntax      string
+	indent      int
+	format      string
+	input       string
+	inputFormat string
+	output      string
+
+	// Flags of generate.
+	pkg     string
+	name    string
+	comment string
+
+	// Flags of convert.
+	to string
+
+	gen generator.Generator
+}
+
+// Run executes the command with arguments and returns the exit code.
+// The first argument is the name of the command. If it is omitted,
+// generate is executed.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == migrateName {
+		// Migrate works with files instead of patterns.
+		return runMigrate(args[1:], stdout, stderr)
+	}
+
+	cmd, args, ok := findCommand(args)
+	if !ok {
+		printUsage(stderr)
+
+		return ExitUsage
+	}
+
+	if cmd.name == "" {
+		printUsage(stdout)
+
+		return ExitOK
+	}
+
+	cfg, patterns, err := parseArgs(cmd, args, stdin, stderr)
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case err != nil:
+		fmt.Fprintf(stderr, "rex %s: %s\n", cmd.name, err)
+
+		return ExitUsage
+	}
+
+	out := stdout
+
+	if cfg.output != "" {
+		file, err := os.Create(cfg.output)
+		if err != nil {
+			fmt.Fprintf(stderr, "rex %s: %s\n", cmd.name, err)
+
+			return ExitFailure
+		}
+
+		defer func() { _ = file.Close() }()
+
+		out = file
+	}
+
+	w := resultWriter{
+		cmd:    cmd,
+		format: cfg.format,
+		batch:  cfg.input != "",
+		stdout: out,
+		stderr: stderr,
+	}
+
+	if cmd.batch != nil && w.batch && w.format == formatText {
+		return cmd.batch(cfg, patterns, w)
+	}
+
+	exitCode := ExitOK
+
+	for _, p := range patterns {
+		result, err := cmd.run(cfg, p)
+		if err != nil {
+			exitCode = ExitFailure
+		}
+
+		w.write(p, result, err)
+	}
+
+	return exitCode
+}
+
+// findCommand returns the command by the first argument. The empty
+// command means help. If the first argument is not a command, a single
+// argument is the pattern for generate.
+func findCommand(args []string) (command, []string, bool) {
+	if len(args) == 0 {
+		return command{}, nil, false
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		return command{}, nil, true
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd, args[1:], true
+		}
+	}
+
+	if len(args) == 1 {
+		// Backward compatibility: `rex <regex>`. Flags are not parsed,
+		// so patterns can start with a dash: `rex '-?\d+'`.
+		return generateCommand(), []string{"--", args[0]}, true
+	}
+
+	// Backward compatibility: `rex [flags] <regex>`.
+	return generateCommand(), args, true
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: rex <command> [flags] [regex]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+
+	fmt.Fprintf(w, "  %-10s %s\n", migrateName, migrateDescription)
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Patterns are read from -input in the batch mode. Run `rex <command> -h` for flags.")
+	fmt.Fprintln(w, "Use -- before patterns that start with a dash or are named as commands: `rex -- test`.")
+}
+
+func parseArgs(cmd command, args []string, stdin io.Reader, stderr io.Writer) (*config, []pattern, error) {
+	cfg := &config{
+		syntax:      "perl",
+		indent:      0,
+		format:      formatText,
+		input:       "",
+		inputFormat: inputLines,
+		output:      "",
+		pkg:         "",
+		name:        "",
+		comment:     "",
+		to:          "",
+		gen:         generator.New(),
+	}
+
+	fs := flag.NewFlagSet("rex "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.syntax, "syntax", cfg.syntax, "syntax of patterns: perl or posix")
+	fs.IntVar(&cfg.indent, "indent", cfg.indent, "number of spaces for indentation, tabs are used if it is 0")
+	fs.StringVar(&cfg.format, "format", cfg.format, "output format: text or json")
+	fs.StringVar(&cfg.input, "input", cfg.input, "file with patterns for the batch mode, - is stdin")
+	fs.StringVar(&cfg.inputFormat, "input-format", cfg.inputFormat, "format of -input: lines or json")
+	fs.StringVar(&cfg.output, "output", cfg.output, "output file, default is stdout")
+
+	if cmd.flags != nil {
+		cmd.flags(fs, cfg)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: rex %s [flags] [regex]\n\n%s.\n\n", cmd.name, cmd.description)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if cmd.validate != nil {
+		if err := cmd.validate(cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	patterns, err := cfg.patterns(fs.Args(), stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, patterns, nil
+}
+
+func (cfg *config) validate() error {
+	switch strings.ToLower(cfg.syntax) {
+	case "perl":
+		cfg.gen.Syntax = syntax.Perl
+	case "posix":
+		cfg.gen.Syntax = syntax.POSIX
+	default:
+		return fmt.Errorf("%w: unknown syntax %q", errUsage, cfg.syntax)
+	}
+
+	switch {
+	case cfg.indent < 0:
+		return fmt.Errorf("%w: negative indent", errUsage)
+	case cfg.indent > 0:
+		cfg.gen.Indent = strings.Repeat(" ", cfg.indent)
+	}
+
+	if cfg.format != formatText && cfg.format != formatJSON {
+		return fmt.Errorf("%w: unknown format %q", errUsage, cfg.format)
+	}
+
+	return nil
+}
+
+// patterns returns patterns from arguments or from the input.
+func (cfg *config) patterns(args []string, stdin io.Reader) ([]pattern, error) {
+	if cfg.input == "" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%w: expected one regex, got %d arguments", errUsage, len(args))
+		}
+
+		if args[0] == "" {
+			return nil, fmt.Errorf("%w: given regex is empty", errUsage)
+		}
+
+		return []pattern{{Name: "", Pattern: args[0]}}, nil
+	}
+
+	if len(args) != 0 {
+		return nil, fmt.Errorf("%w: unexpected arguments in the batch mode", errUsage)
+	}
+
+	r := stdin
+
+	if cfg.input != "-" {
+		file, err := os.Open(cfg.input)
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = file.Close() }()
+
+		r = file
+	}
+
+	return readPatterns(r, cfg.inputFormat)
+}
@@ -0,0 +1,443 @@
+package cli_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/cli"
+)
+
+type cliTestCase struct {
+	name     string
+	args     []string
+	stdin    string
+	exitCode int
+	stdout   string
+	stderr   string
+}
+
+func (tc cliTestCase) run(t *testing.T) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	exitCode := cli.Run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+
+	if exitCode != tc.exitCode {
+		t.Errorf("Exit code: expected %d, got %d, stderr: %s", tc.exitCode, exitCode, stderr.String())
+	}
+
+	if stdout.String() != tc.stdout {
+		t.Errorf("Stdout:\nexpected:\n%s\ngot:\n%s", tc.stdout, stdout.String())
+	}
+
+	if !strings.Contains(stderr.String(), tc.stderr) {
+		t.Errorf("Stderr:\nexpected to contain: %s\ngot: %s", tc.stderr, stderr.String())
+	}
+}
+
+// nolint: funlen // Test cases.
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []cliTestCase{{
+		name:     "generate",
+		args:     []string{"generate", `\d+`},
+		exitCode: cli.ExitOK,
+		stdout: "rex.New(\n" +
+			"\trex.Chars.Digits().Repeat().OneOrMore(),\n" +
+			")\n",
+	}, {
+		name:     "generate_without_command",
+		args:     []string{"-indent", "2", `(a)`},
+		exitCode: cli.ExitOK,
+		stdout: "rex.New(\n" +
+			"  rex.Group.Define(\n" +
+			"    rex.Chars.Single('a'),\n" +
+			"  ),\n" +
+			")\n",
+	}, {
+		name:     "generate_dash_pattern",
+		args:     []string{`-?\d`},
+		exitCode: cli.ExitOK,
+		stdout: "rex.New(\n" +
+			"\trex.Chars.Single('-').Repeat().ZeroOrOne(),\n" +
+			"\trex.Chars.Digits(),\n" +
+			")\n",
+	}, {
+		name:     "generate_command_pattern",
+		args:     []string{"--", "test"},
+		exitCode: cli.ExitOK,
+		stdout: "rex.New(\n" +
+			"\trex.Common.Text(\"test\"),\n" +
+			")\n",
+	}, {
+		name:     "generate_flags_and_dash_pattern",
+		args:     []string{"generate", "-indent", "1", "--", `-a`},
+		exitCode: cli.ExitOK,
+		stdout: "rex.New(\n" +
+			" rex.Common.Text(\"-a\"),\n" +
+			")\n",
+	}, {
+		name:     "generate_file",
+		args:     []string{"generate", "-package", "main", "-name", "re", "-comment", "re is an example.", `a`},
+		exitCode: cli.ExitOK,
+		stdout: "// Code generated by rex generator. DO NOT EDIT.\n\n" +
+			"package main\n\n" +
+			"import \"github.com/hedhyw/rex/pkg/rex\"\n\n" +
+			"// re is an example.\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Single('a'),\n" +
+			").MustCompile()\n",
+	}, {
+		name:     "generate_posix",
+		args:     []string{"generate", "-syntax", "posix", `\d`},
+		exitCode: cli.ExitFailure,
+		stderr:   "invalid escape sequence",
+	}, {
+		name:     "generate_invalid",
+		args:     []string{"generate", `(`},
+		exitCode: cli.ExitFailure,
+		stderr:   "rex generate: (: failed to parse regexp",
+	}, {
+		name:     "generate_batch_lines",
+		args:     []string{"generate", "-input", "-"},
+		stdin:    "a\n\nb+\n",
+		exitCode: cli.ExitOK,
+		stdout: "// a\n" +
+			"rex.New(\n" +
+			"\trex.Chars.Single('a'),\n" +
+			")\n\n" +
+			"// b+\n" +
+			"rex.New(\n" +
+			"\trex.Chars.Single('b').Repeat().OneOrMore(),\n" +
+			")\n\n",
+	}, {
+		name:     "generate_batch_json",
+		args:     []string{"generate", "-input", "-", "-input-format", "json", "-format", "json", "-package", "p"},
+		stdin:    `["a", {"name": "re", "pattern": "("}]`,
+		exitCode: cli.ExitFailure,
+		stdout: `{"pattern":"a","output":"rex.New(\n\trex.Chars.Single('a'),\n)"}` + "\n" +
+			`{"name":"re","pattern":"(","error":"failed to parse regexp: error parsing regexp: missing closing ): ` + "`(`" + `"}` + "\n",
+	}, {
+		name:     "generate_batch_name",
+		args:     []string{"generate", "-input", "-", "-name", "re"},
+		exitCode: cli.ExitUsage,
+		stderr:   "-name can't be used in the batch mode",
+	}, {
+		name:     "explain",
+		args:     []string{"explain", "-indent", "1", `a|bc*`},
+		exitCode: cli.ExitOK,
+		stdout: "one of:\n" +
+			" text \"a\"\n" +
+			" sequence of:\n" +
+			"  text \"b\"\n" +
+			"  zero or more of:\n" +
+			"   text \"c\"\n",
+	}, {
+		name:     "test",
+		args:     []string{"test", `^[\w.]+@\w+\.com$`},
+		exitCode: cli.ExitOK,
+		stdout:   "ok\n",
+	}, {
+		name:     "test_batch",
+		args:     []string{"test", "-input", "-"},
+		stdin:    "a+\n(\n[0-9]{2}\n",
+		exitCode: cli.ExitFailure,
+		stdout:   "ok\ta+\nok\t[0-9]{2}\n",
+		stderr:   "rex test: (: failed to parse regexp",
+	}, {
+		name:     "convert",
+		args:     []string{"convert", "-to", "ecmascript", `(?P<num>\d+)`},
+		exitCode: cli.ExitOK,
+		stdout:   "(?<num>\\d+)\n",
+	}, {
+		name:     "convert_batch",
+		args:     []string{"convert", "-input", "-"},
+		stdin:    "[0-9]\n(?:a)\n",
+		exitCode: cli.ExitOK,
+		stdout:   "\\d\na\n",
+	}, {
+		name:     "convert_unknown_dialect",
+		args:     []string{"convert", "-to", "unknown", `a`},
+		exitCode: cli.ExitUsage,
+		stderr:   `unknown dialect "unknown"`,
+	}, {
+		name:     "no_arguments",
+		args:     nil,
+		exitCode: cli.ExitUsage,
+		stderr:   "Usage: rex <command>",
+	}, {
+		name:     "migrate_without_paths",
+		args:     []string{"migrate", "-w"},
+		exitCode: cli.ExitUsage,
+		stderr:   "expected paths",
+	}, {
+		name:     "migrate_not_found",
+		args:     []string{"migrate", "not_found.go"},
+		exitCode: cli.ExitFailure,
+		stderr:   "not_found.go",
+	}, {
+		name:     "command_help",
+		args:     []string{"explain", "-h"},
+		exitCode: cli.ExitOK,
+		stderr:   "Usage: rex explain",
+	}, {
+		name:     "too_many_arguments",
+		args:     []string{"explain", "a", "b"},
+		exitCode: cli.ExitUsage,
+		stderr:   "expected one regex, got 2 arguments",
+	}, {
+		name:     "empty_regex",
+		args:     []string{"explain", ""},
+		exitCode: cli.ExitUsage,
+		stderr:   "given regex is empty",
+	}, {
+		name:     "unknown_syntax",
+		args:     []string{"explain", "-syntax", "pcre", "a"},
+		exitCode: cli.ExitUsage,
+		stderr:   `unknown syntax "pcre"`,
+	}, {
+		name:     "unknown_format",
+		args:     []string{"explain", "-format", "xml", "a"},
+		exitCode: cli.ExitUsage,
+		stderr:   `unknown format "xml"`,
+	}, {
+		name:     "unknown_input_format",
+		args:     []string{"explain", "-input", "-", "-input-format", "xml"},
+		exitCode: cli.ExitUsage,
+		stderr:   `unknown input format "xml"`,
+	}, {
+		name:     "invalid_json",
+		args:     []string{"explain", "-input", "-", "-input-format", "json"},
+		stdin:    "{",
+		exitCode: cli.ExitUsage,
+		stderr:   "reading input",
+	}}
+
+	for _, testCaseNotInParallel := range testCases {
+		testCase := testCaseNotInParallel
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testCase.run(t)
+		})
+	}
+}
+
+func TestRunGenerateBatchFile(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+
+	args := []string{"generate", "-input", "-", "-input-format", "json", "-package", "p"}
+	stdin := `[{"name": "aRegExp", "pattern": "a"}, {"name": "bRegExp", "pattern": "b+"}]`
+
+	if exitCode := cli.Run(args, strings.NewReader(stdin), &stdout, &stderr); exitCode != cli.ExitOK {
+		t.Fatalf("Exit code: %d, stderr: %s", exitCode, stderr.String())
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", stdout.Bytes(), parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Output is not a Go file: %s\n%s", err, stdout.String())
+	}
+
+	if len(file.Imports) != 1 || len(file.Decls) != 3 {
+		t.Errorf("Expected one import and two variables:\n%s", stdout.String())
+	}
+
+	if strings.Count(stdout.String(), "DO NOT EDIT") != 1 {
+		t.Errorf("Expected one header:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+
+	stdin = `[{"name": "aRegExp", "pattern": "a"}, "b"]`
+
+	if exitCode := cli.Run(args, strings.NewReader(stdin), &stdout, &stderr); exitCode != cli.ExitFailure {
+		t.Fatalf("Exit code: %d", exitCode)
+	}
+
+	if !strings.Contains(stderr.String(), "rex generate: b: name is required") {
+		t.Errorf("Unexpected stderr: %s", stderr.String())
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "p.go", stdout.Bytes(), 0); err != nil {
+		t.Errorf("Output is not a Go file: %s\n%s", err, stdout.String())
+	}
+}
+
+func TestRunHelpStdout(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := cli.Run([]string{"help"}, strings.NewReader(""), &stdout, &stderr); exitCode != cli.ExitOK {
+		t.Fatalf("Exit code: %d", exitCode)
+	}
+
+	for _, command := range []string{"generate", "explain", "test", "convert", "migrate"} {
+		if !strings.Contains(stdout.String(), command) {
+			t.Errorf("Command %q is not in the usage: %s", command, stdout.String())
+		}
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "patterns.txt")
+	output := filepath.Join(dir, "converted.txt")
+
+	if err := os.WriteFile(input, []byte("[0-9]+\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	args := []string{"convert", "-to", "PCRE", "-input", input, "-output", output}
+
+	if exitCode := cli.Run(args, strings.NewReader(""), &stdout, &stderr); exitCode != cli.ExitOK {
+		t.Fatalf("Exit code: %d, stderr: %s", exitCode, stderr.String())
+	}
+
+	actual, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != "\\d+\n" {
+		t.Errorf("Expected: \\d+, got: %s", actual)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("Unexpected stdout: %s", stdout.String())
+	}
+}
+
+// nolint: funlen // Files setup.
+func TestRunMigrate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	const (
+		src = "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"var re = regexp.MustCompile(`a`)\n"
+		migrated = "package p\n\n" +
+			"import \"github.com/hedhyw/rex/pkg/rex\"\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Single('a'),\n" +
+			").MustCompile()\n"
+	)
+
+	files := map[string]string{
+		"p.go":                src,
+		"sub/p.go":            src,
+		"testdata/p.go":       src,
+		"vendor/v/p.go":       src,
+		"sub/reported.go":     "package p\n\nimport \"regexp\"\n\nvar re = regexp.MustCompile(`a**`)\n",
+		"sub/not_go.txt":      src,
+		"_ignored/p.go":       src,
+		".hidden/sub/p.go":    src,
+		"sub/sub/nochange.go": "package p\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	args := []string{"migrate", "-w", filepath.Join(dir, "p.go"), filepath.Join(dir, "sub") + "/..."}
+
+	if exitCode := cli.Run(args, strings.NewReader(""), &stdout, &stderr); exitCode != cli.ExitFailure {
+		t.Errorf("Exit code: %d, stderr: %s", exitCode, stderr.String())
+	}
+
+	if !strings.Contains(stderr.String(), "reported.go:5:10: \"a**\": failed to parse regexp") {
+		t.Errorf("Report is not found in stderr: %s", stderr.String())
+	}
+
+	expectedStdout := filepath.Join(dir, "p.go") + "\n" + filepath.Join(dir, "sub", "p.go") + "\n"
+	if stdout.String() != expectedStdout {
+		t.Errorf("Stdout:\nexpected:\n%s\ngot:\n%s", expectedStdout, stdout.String())
+	}
+
+	for name, content := range files {
+		expected := content
+		if name == "p.go" || name == "sub/p.go" {
+			expected = migrated
+		}
+
+		actual, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(actual) != expected {
+			t.Errorf("File %s:\nexpected:\n%s\ngot:\n%s", name, expected, actual)
+		}
+	}
+}
+
+func TestRunMigrateDiff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.go")
+	src := "package p\n\nimport \"regexp\"\n\nvar re = regexp.MustCompile(`a`)\n"
+
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	if exitCode := cli.Run([]string{"migrate", dir}, strings.NewReader(""), &stdout, &stderr); exitCode != cli.ExitOK {
+		t.Fatalf("Exit code: %d, stderr: %s", exitCode, stderr.String())
+	}
+
+	slashPath := filepath.ToSlash(path)
+	expected := "--- a/" + slashPath + "\n" +
+		"+++ b/" + slashPath + "\n" +
+		"@@ -1,5 +1,7 @@\n" +
+		" package p\n" +
+		" \n" +
+		"-import \"regexp\"\n" +
+		"+import \"github.com/hedhyw/rex/pkg/rex\"\n" +
+		" \n" +
+		"-var re = regexp.MustCompile(`a`)\n" +
+		"+var re = rex.New(\n" +
+		"+\trex.Chars.Single('a'),\n" +
+		"+).MustCompile()\n"
+
+	if stdout.String() != expected {
+		t.Errorf("Stdout:\nexpected:\n%s\ngot:\n%s", expected, stdout.String())
+	}
+
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != src {
+		t.Errorf("File is changed without -w: %s", actual)
+	}
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/generator"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// dialects are supported by convert.
+var dialects = []dialect.Dialect{
+	dialect.RE2,
+	dialect.PCRE,
+	dialect.ECMAScript,
+	dialect.POSIXExtended,
+	dialect.DotNET,
+}
+
+func generateCommand() command {
+	return command{
+		name:        "generate",
+		description: "Generate rex code or a Go file with a variable, if a name is given",
+		lineOutput:  false,
+		showPattern: false,
+		flags: func(fs *flag.FlagSet, cfg *config) {
+			// GOPACKAGE is set by `go generate`.
+			fs.StringVar(&cfg.pkg, "package", os.Getenv("GOPACKAGE"), "package name of the generated file")
+			fs.StringVar(&cfg.name, "name", "", "variable name, if it is set a Go file is generated")
+			fs.StringVar(&cfg.comment, "comment", "", "doc comment of the variable")
+		},
+		validate: func(cfg *config) error {
+			if cfg.name != "" && cfg.input != "" {
+				return fmt.Errorf("%w: -name can't be used in the batch mode, set names in the JSON input", errUsage)
+			}
+
+			return nil
+		},
+		batch: generateBatch,
+		run: func(cfg *config, p pattern) (string, error) {
+			name := p.Name
+			if name == "" {
+				name = cfg.name
+			}
+
+			if name == "" {
+				return cfg.gen.Code(p.Pattern)
+			}
+
+			file, err := cfg.gen.File(p.Pattern, generator.FileOptions{
+				Package: cfg.pkg,
+				Name:    name,
+				Comment: cfg.comment,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			return string(file), nil
+		},
+	}
+}
+
+// errNameRequired is reported for patterns without names in the batch
+// mode, if other patterns are named.
+var errNameRequired = errors.New("name is required, if other patterns are named")
+
+// generateBatch writes code of patterns without names, and one Go file
+// with variables of named patterns. If some patterns are named, all
+// patterns should be named, so the output is a valid Go file.
+func generateBatch(cfg *config, patterns []pattern, w resultWriter) int {
+	var (
+		vars     []generator.FileVar
+		named    bool
+		exitCode = ExitOK
+	)
+
+	for _, p := range patterns {
+		named = named || p.Name != ""
+	}
+
+	for _, p := range patterns {
+		code, err := cfg.gen.Code(p.Pattern)
+
+		switch {
+		case err == nil && named && p.Name == "":
+			err = errNameRequired
+		case err == nil && named:
+			vars = append(vars, generator.FileVar{Name: p.Name, Regex: p.Pattern, Comment: ""})
+
+			continue
+		}
+
+		if err != nil {
+			exitCode = ExitFailure
+		}
+
+		w.write(p, code, err)
+	}
+
+	if len(vars) == 0 {
+		return exitCode
+	}
+
+	file, err := cfg.gen.FileVars(cfg.pkg, vars)
+	if err != nil {
+		fmt.Fprintf(w.stderr, "rex %s: %s\n", w.cmd.name, err)
+
+		return ExitFailure
+	}
+
+	_, _ = w.stdout.Write(file)
+
+	return exitCode
+}
+
+func explainCommand() command {
+	return command{
+		name:        "explain",
+		description: "Describe the regex in plain words",
+		lineOutput:  false,
+		showPattern: false,
+		flags:       nil,
+		validate:    nil,
+		batch:       nil,
+		run: func(cfg *config, p pattern) (string, error) {
+			return cfg.gen.Explain(p.Pattern)
+		},
+	}
+}
+
+func testCommand() command {
+	return command{
+		name:        "test",
+		description: "Check that the generated code builds an equivalent regex",
+		lineOutput:  true,
+		showPattern: true,
+		flags:       nil,
+		validate:    nil,
+		batch:       nil,
+		run: func(cfg *config, p pattern) (string, error) {
+			if err := cfg.gen.Verify(p.Pattern); err != nil {
+				return "", err
+			}
+
+			return "ok", nil
+		},
+	}
+}
+
+func convertCommand() command {
+	return command{
+		name:        "convert",
+		description: "Convert the regex to another dialect",
+		lineOutput:  true,
+		showPattern: false,
+		flags: func(fs *flag.FlagSet, cfg *config) {
+			names := make([]string, 0, len(dialects))
+			for _, d := range dialects {
+				names = append(names, string(d))
+			}
+
+			fs.StringVar(&cfg.to, "to", string(dialect.RE2), "target dialect: "+strings.Join(names, ", "))
+		},
+		validate: func(cfg *config) error {
+			if _, ok := findDialect(cfg.to); !ok {
+				return fmt.Errorf("%w: unknown dialect %q", errUsage, cfg.to)
+			}
+
+			return nil
+		},
+		batch: nil,
+		run: func(cfg *config, p pattern) (string, error) {
+			tokens, err := cfg.gen.Tokens(p.Pattern)
+			if err != nil {
+				return "", err
+			}
+
+			d, _ := findDialect(cfg.to)
+
+			return rex.New(tokens...).StringFor(d)
+		},
+	}
+}
+
+// findDialect returns the dialect by its name ignoring case.
+func findDialect(name string) (dialect.Dialect, bool) {
+	for _, d := range dialects {
+		if strings.EqualFold(string(d), name) {
+			return d, true
+		}
+	}
+
+	return "", false
+}
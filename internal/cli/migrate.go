@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/generator"
+	"github.com/hedhyw/rex/internal/migrate"
+)
+
+const (
+	migrateName        = "migrate"
+	migrateDescription = "Rewrite regexp calls with constant patterns in Go files to rex builders"
+)
+
+// runMigrate prints the diff of Go files in paths, or rewrites them in
+// place. Paths are files, directories or directories with all
+// subdirectories: `./...`.
+func runMigrate(args []string, stdout io.Writer, stderr io.Writer) int {
+	var write bool
+
+	flags := flag.NewFlagSet("rex migrate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.BoolVar(&write, "w", false, "write changes to files instead of printing the diff")
+
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: rex migrate [flags] <path>...\n\n%s.\n", migrateDescription)
+		fmt.Fprintf(flags.Output(), "Patterns without rex equivalents are reported and kept as is.\n\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case err != nil:
+		return ExitUsage
+	case flags.NArg() == 0:
+		fmt.Fprintln(stderr, "rex migrate: usage: expected paths, for example ./...")
+
+		return ExitUsage
+	}
+
+	files, err := goFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "rex migrate: %s\n", err)
+
+		return ExitFailure
+	}
+
+	exitCode := ExitOK
+
+	for _, filename := range files {
+		if err := migrateFile(filename, write, stdout, stderr); err != nil {
+			exitCode = ExitFailure
+		}
+	}
+
+	return exitCode
+}
+
+// migrateFile returns an error if the file can't be migrated or some
+// patterns are reported.
+func migrateFile(filename string, write bool, stdout io.Writer, stderr io.Writer) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(stderr, "rex migrate: %s\n", err)
+
+		return err
+	}
+
+	result, err := migrate.File(generator.New(), filename, src)
+	if err != nil {
+		fmt.Fprintf(stderr, "rex migrate: %s: %s\n", filename, err)
+
+		return err
+	}
+
+	for _, report := range result.Reports {
+		fmt.Fprintf(stderr, "rex migrate: %s\n", report)
+	}
+
+	if result.Changed() {
+		if write {
+			err = writeFile(filename, result.Migrated)
+			if err != nil {
+				fmt.Fprintf(stderr, "rex migrate: %s\n", err)
+
+				return err
+			}
+
+			fmt.Fprintln(stdout, filename)
+		} else {
+			fmt.Fprint(stdout, migrate.Diff(filepath.ToSlash(filename), result.Original, result.Migrated))
+		}
+	}
+
+	if len(result.Reports) > 0 {
+		return fmt.Errorf("%s: %d patterns are not migrated", filename, len(result.Reports))
+	}
+
+	return nil
+}
+
+// writeFile keeps permissions of the file.
+func writeFile(filename string, data []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, info.Mode().Perm())
+}
+
+// goFiles returns Go files in paths. Directories vendor, testdata and
+// directories, that start with `.` or `_`, are skipped as the go tool
+// does.
+func goFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		root := path
+
+		recursive := strings.HasSuffix(path, "...")
+		if recursive {
+			root = filepath.Clean(strings.TrimSuffix(path, "..."))
+		}
+
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, root)
+
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				if path != root && (!recursive || isIgnoredDir(entry.Name())) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if strings.HasSuffix(path, ".go") {
+				files = append(files, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func isIgnoredDir(name string) bool {
+	return name == "vendor" || name == "testdata" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
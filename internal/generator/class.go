@@ -0,0 +1,379 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"unicode"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// maxInlineClassParts is the maximum number of parts of the class,
+// that is written in one line.
+const maxInlineClassParts = 2
+
+// knownClass is a class of characters that has a dedicated rex call.
+type knownClass struct {
+	code   string
+	token  base.ClassToken
+	ranges []rune
+}
+
+// shorthandClasses are preferred to other representations. They are
+// also used as parts of bigger classes, the order defines priority.
+var shorthandClasses = []knownClass{
+	{code: "rex.Chars.WordCharacter()", token: rex.Chars.WordCharacter(), ranges: charclass.Word},
+	{code: "rex.Chars.Digits()", token: rex.Chars.Digits(), ranges: charclass.Digits},
+	{code: "rex.Chars.Whitespace()", token: rex.Chars.Whitespace(), ranges: charclass.Whitespace},
+}
+
+// namedClasses are POSIX classes, unicode categories and scripts. They
+// are computed on the first use.
+var namedClasses struct {
+	once    sync.Once
+	classes []knownClass
+}
+
+func getNamedClasses() []knownClass {
+	namedClasses.once.Do(func() {
+		posix := []knownClass{
+			{code: "rex.Chars.Alphanumeric()", token: rex.Chars.Alphanumeric(), ranges: charclass.POSIX["alnum"]},
+			{code: "rex.Chars.Alphabetic()", token: rex.Chars.Alphabetic(), ranges: charclass.POSIX["alpha"]},
+			{code: "rex.Chars.ASCII()", token: rex.Chars.ASCII(), ranges: charclass.POSIX["ascii"]},
+			{code: "rex.Chars.Blank()", token: rex.Chars.Blank(), ranges: charclass.POSIX["blank"]},
+			{code: "rex.Chars.Control()", token: rex.Chars.Control(), ranges: charclass.POSIX["cntrl"]},
+			{code: "rex.Chars.Graphical()", token: rex.Chars.Graphical(), ranges: charclass.POSIX["graph"]},
+			{code: "rex.Chars.Lower()", token: rex.Chars.Lower(), ranges: charclass.POSIX["lower"]},
+			{code: "rex.Chars.Printable()", token: rex.Chars.Printable(), ranges: charclass.POSIX["print"]},
+			{code: "rex.Chars.Punctuation()", token: rex.Chars.Punctuation(), ranges: charclass.POSIX["punct"]},
+			{code: "rex.Chars.Upper()", token: rex.Chars.Upper(), ranges: charclass.POSIX["upper"]},
+			{code: "rex.Chars.HexDigits()", token: rex.Chars.HexDigits(), ranges: charclass.POSIX["xdigit"]},
+		}
+
+		namedClasses.classes = posix
+
+		for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts} {
+			for _, name := range sortedKeys(tables) {
+				namedClasses.classes = append(namedClasses.classes, knownClass{
+					code:   fmt.Sprintf("rex.Chars.UnicodeByName(%q)", name),
+					token:  rex.Chars.UnicodeByName(name),
+					ranges: charclass.FromTable(tables[name]),
+				})
+			}
+		}
+	})
+
+	return namedClasses.classes
+}
+
+func sortedKeys(tables map[string]*unicode.RangeTable) []string {
+	keys := make([]string, 0, len(tables))
+	for key := range tables {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// convertClass maps the class to rex calls. Known classes are preferred,
+// then single characters and ranges, then a composition of them.
+func convertClass(ranges []rune) *node {
+	if class, ok := findKnownClass(ranges); ok {
+		return leaf(class.code, class.token)
+	}
+
+	negated := charclass.Negate(ranges)
+
+	if class, ok := findKnownClass(negated); ok {
+		return leaf(class.code+".Not()", class.token.Not())
+	}
+
+	if len(negated) < len(ranges) {
+		return convertComposedClass(negated, true, false)
+	}
+
+	return convertComposedClass(ranges, false, false)
+}
+
+// convertFoldedClass maps the class of a case-insensitive expression to
+// a case-insensitive group, so characters, that are added by case folding,
+// like the Kelvin sign for `k`, are not listed. It returns false if case
+// folding doesn't change the class.
+func convertFoldedClass(ranges []rune) (*node, bool) {
+	if !reflect.DeepEqual(charclass.FoldCase(ranges), ranges) {
+		return nil, false
+	}
+
+	negated := charclass.Negate(ranges)
+	isNegated := len(negated) < len(ranges)
+
+	if isNegated {
+		ranges = negated
+	}
+
+	reduced := unfoldCase(ranges)
+	if reflect.DeepEqual(reduced, ranges) {
+		return nil, false
+	}
+
+	var n *node
+
+	switch class, ok := findFoldedClass(ranges, reduced); {
+	case ok && !isNegated && len(reduced) == 2 && reduced[0] == reduced[1]:
+		// A single character is parsed as a literal, but the expression
+		// contains a class.
+		n = group("rex.Common.Class(", []*node{leaf(class.code, class.token)}, ")", rex.Common.Class(class.token))
+		n.inline = true
+	case ok && isNegated:
+		n = leaf(class.code+".Not()", class.token.Not())
+	case ok:
+		n = leaf(class.code, class.token)
+	default:
+		n = convertComposedClass(reduced, isNegated, true)
+	}
+
+	return call(
+		"rex.Flags.CaseInsensitive().Group(", []*node{n}, ")",
+		rex.Flags.CaseInsensitive().Group(n.token),
+	), true
+}
+
+// findFoldedClass returns the known class of the reduced class, or the
+// known class, that is equal to the class after case folding.
+func findFoldedClass(ranges []rune, reduced []rune) (knownClass, bool) {
+	if class, ok := findKnownClass(reduced); ok {
+		return class, true
+	}
+
+	for _, classes := range [][]knownClass{shorthandClasses, getNamedClasses()} {
+		for _, class := range classes {
+			if reflect.DeepEqual(charclass.FoldCase(class.ranges), ranges) {
+				return class, true
+			}
+		}
+	}
+
+	return knownClass{}, false
+}
+
+// unfoldCase keeps one character of each case folding orbit of the
+// class. Lower case ASCII letters are preferred, then ASCII, then lower
+// case letters and then smaller code points.
+func unfoldCase(ranges []rune) []rune {
+	var reduced []rune
+
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			if foldRepresentative(r) == r {
+				reduced = append(reduced, r, r)
+			}
+		}
+	}
+
+	return charclass.Normalize(reduced)
+}
+
+// foldRepresentative returns the preferred character of the case
+// folding orbit of r.
+func foldRepresentative(r rune) rune {
+	rank := func(r rune) int {
+		switch {
+		case r <= unicode.MaxASCII && unicode.IsLower(r):
+			return 0
+		case r <= unicode.MaxASCII:
+			return 1
+		case unicode.IsLower(r):
+			return 2
+		default:
+			return 3
+		}
+	}
+
+	best := r
+
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if rank(f) < rank(best) || (rank(f) == rank(best) && f < best) {
+			best = f
+		}
+	}
+
+	return best
+}
+
+func findKnownClass(ranges []rune) (knownClass, bool) {
+	for _, class := range shorthandClasses {
+		if reflect.DeepEqual(class.ranges, ranges) {
+			return class, true
+		}
+	}
+
+	if len(ranges) == 2 {
+		return rangeClass(ranges[0], ranges[1]), true
+	}
+
+	for _, class := range getNamedClasses() {
+		if reflect.DeepEqual(class.ranges, ranges) {
+			return class, true
+		}
+	}
+
+	return knownClass{}, false
+}
+
+func rangeClass(from rune, to rune) knownClass {
+	if from == to {
+		return knownClass{
+			code:   fmt.Sprintf("rex.Chars.Single(%q)", from),
+			token:  rex.Chars.Single(from),
+			ranges: []rune{from, to},
+		}
+	}
+
+	return knownClass{
+		code:   fmt.Sprintf("rex.Chars.Range(%q, %q)", from, to),
+		token:  rex.Chars.Range(from, to),
+		ranges: []rune{from, to},
+	}
+}
+
+// convertComposedClass composes the class of shorthands and named
+// classes, that it contains, and remaining characters and ranges.
+// If foldCase is set, the class is matched case-insensitively, so parts
+// are compared after case folding.
+func convertComposedClass(ranges []rune, negated bool, foldCase bool) *node {
+	var parts []knownClass
+
+	composer := classComposer{ranges: ranges, foldCase: foldCase}
+	if foldCase {
+		composer.ranges = charclass.FoldCase(ranges)
+	}
+
+	remaining := ranges
+
+	for _, class := range shorthandClasses {
+		if len(remaining) == 0 {
+			break
+		}
+
+		classRanges, ok := composer.part(class)
+		if !ok {
+			continue
+		}
+
+		rest := charclass.Subtract(remaining, classRanges)
+		if reflect.DeepEqual(rest, remaining) {
+			// The shorthand doesn't cover anything new.
+			continue
+		}
+
+		parts = append(parts, class)
+		remaining = rest
+	}
+
+	for len(remaining) > 0 {
+		class, classRanges, ok := composer.largestNamedPart(remaining)
+		if !ok {
+			break
+		}
+
+		parts = append(parts, class)
+		remaining = charclass.Subtract(remaining, classRanges)
+	}
+
+	for i := 0; i < len(remaining); i += 2 {
+		parts = append(parts, rangeClass(remaining[i], remaining[i+1]))
+	}
+
+	children := make([]*node, 0, len(parts))
+	tokens := make([]dialect.ClassToken, 0, len(parts))
+
+	for _, part := range parts {
+		children = append(children, leaf(part.code, part.token))
+		tokens = append(tokens, part.token)
+	}
+
+	head, token := "rex.Common.Class(", rex.Common.Class(tokens...)
+	if negated {
+		head, token = "rex.Common.NotClass(", rex.Common.NotClass(tokens...)
+	}
+
+	n := group(head, children, ")", token)
+	n.inline = len(children) <= maxInlineClassParts
+
+	return n
+}
+
+// classComposer finds known classes, that are parts of the class.
+type classComposer struct {
+	// ranges of the class, they are folded if foldCase is set.
+	ranges   []rune
+	foldCase bool
+}
+
+// part returns ranges, that the known class matches, if it is a subset
+// of the class.
+func (c classComposer) part(class knownClass) ([]rune, bool) {
+	// Case folding only adds characters, so it is checked after
+	// the cheaper check.
+	if len(charclass.Subtract(class.ranges, c.ranges)) != 0 {
+		return nil, false
+	}
+
+	if !c.foldCase {
+		return class.ranges, true
+	}
+
+	folded := charclass.FoldCase(class.ranges)
+
+	return folded, len(charclass.Subtract(folded, c.ranges)) == 0
+}
+
+// minNamedPartRanges is the minimum number of ranges of a named class
+// to be used as a part of a bigger class. Single ranges are clearer.
+const minNamedPartRanges = 2
+
+// largestNamedPart returns the named class, that is a subset of the class
+// and covers the most of remaining characters.
+func (c classComposer) largestNamedPart(remaining []rune) (knownClass, []rune, bool) {
+	var (
+		best        knownClass
+		bestRanges  []rune
+		bestCovered int
+	)
+
+	for _, class := range getNamedClasses() {
+		if len(class.ranges) < 2*minNamedPartRanges {
+			continue
+		}
+
+		classRanges, ok := c.part(class)
+		if !ok {
+			continue
+		}
+
+		covered := countRunes(charclass.Intersect(classRanges, remaining))
+		if covered > bestCovered {
+			best, bestRanges, bestCovered = class, classRanges, covered
+		}
+	}
+
+	return best, bestRanges, bestCovered > 0
+}
+
+// countRunes returns the number of runes in ranges.
+func countRunes(ranges []rune) int {
+	var count int
+
+	for i := 0; i < len(ranges); i += 2 {
+		count += int(ranges[i+1]-ranges[i]) + 1
+	}
+
+	return count
+}
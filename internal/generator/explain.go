@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"regexp/syntax"
+	"strings"
+)
+
+// Explain describes the regex in plain words. Each construction is
+// written on a separate line, nested ones are indented.
+func (g Generator) Explain(regex string) (string, error) {
+	regExpr, err := syntax.Parse(regex, g.Syntax)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse regexp: %w", err)
+	}
+
+	var strBuilder strings.Builder
+
+	explainer{w: &strBuilder, indent: g.Indent}.explain(regExpr, 0)
+
+	return strBuilder.String(), nil
+}
+
+type explainer struct {
+	w      io.StringWriter
+	indent string
+}
+
+func (e explainer) line(depth int, format string, args ...interface{}) {
+	_, _ = e.w.WriteString(strings.Repeat(e.indent, depth) + fmt.Sprintf(format, args...) + "\n")
+}
+
+// nolint: cyclop // One case per an operation.
+func (e explainer) explain(regExpr *syntax.Regexp, depth int) {
+	//nolint: exhaustive // All cases captured in default.
+	switch regExpr.Op {
+	case syntax.OpLiteral:
+		text := string(regExpr.Rune)
+		if regExpr.Flags&syntax.FoldCase != 0 {
+			e.line(depth, "text %q ignoring case", strings.ToLower(text))
+		} else {
+			e.line(depth, "text %q", text)
+		}
+	case syntax.OpCharClass:
+		e.line(depth, "one character of %s", regExpr)
+	case syntax.OpAnyCharNotNL:
+		e.line(depth, "any character except new line")
+	case syntax.OpAnyChar:
+		e.line(depth, "any character")
+	case syntax.OpBeginLine:
+		e.line(depth, "beginning of a line")
+	case syntax.OpEndLine:
+		e.line(depth, "end of a line")
+	case syntax.OpBeginText:
+		e.line(depth, "beginning of the text")
+	case syntax.OpEndText:
+		e.line(depth, "end of the text")
+	case syntax.OpWordBoundary:
+		e.line(depth, "ASCII word boundary")
+	case syntax.OpNoWordBoundary:
+		e.line(depth, "not ASCII word boundary")
+	case syntax.OpEmptyMatch:
+		e.line(depth, "empty string")
+	case syntax.OpNoMatch:
+		e.line(depth, "nothing")
+	case syntax.OpCapture:
+		if regExpr.Name != "" {
+			e.line(depth, "group #%d %q of:", regExpr.Cap, regExpr.Name)
+		} else {
+			e.line(depth, "group #%d of:", regExpr.Cap)
+		}
+
+		e.explain(regExpr.Sub[0], depth+1)
+	case syntax.OpConcat:
+		if depth == 0 {
+			e.explainAll(regExpr.Sub, depth)
+		} else {
+			e.line(depth, "sequence of:")
+			e.explainAll(regExpr.Sub, depth+1)
+		}
+	case syntax.OpAlternate:
+		e.line(depth, "one of:")
+		e.explainAll(regExpr.Sub, depth+1)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		e.line(depth, "%s of:", repetitionDescription(regExpr))
+		e.explain(regExpr.Sub[0], depth+1)
+	default:
+		e.line(depth, "%s", regExpr)
+	}
+}
+
+func (e explainer) explainAll(regExprs []*syntax.Regexp, depth int) {
+	for _, sub := range regExprs {
+		e.explain(sub, depth)
+	}
+}
+
+func repetitionDescription(regExpr *syntax.Regexp) string {
+	var description string
+
+	switch {
+	case regExpr.Op == syntax.OpStar:
+		description = "zero or more"
+	case regExpr.Op == syntax.OpPlus:
+		description = "one or more"
+	case regExpr.Op == syntax.OpQuest:
+		description = "optional"
+	case regExpr.Min == regExpr.Max:
+		description = fmt.Sprintf("exactly %d", regExpr.Min)
+	case regExpr.Max == -1:
+		description = fmt.Sprintf("%d or more", regExpr.Min)
+	default:
+		description = fmt.Sprintf("between %d and %d", regExpr.Min, regExpr.Max)
+	}
+
+	if regExpr.Flags&syntax.NonGreedy != 0 {
+		description += " (prefer fewer)"
+	}
+
+	return description
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+)
+
+// ErrInvalidIdentifier is returned if the package or the variable name
+// is not a valid Go identifier.
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// FileOptions describe the Go file, that is generated by GenerateFile.
+type FileOptions struct {
+	// Package is the name of the package of the file.
+	Package string
+	// Name is the name of the variable with the compiled regex.
+	Name string
+	// Comment is the doc comment of the variable without slashes. It can
+	// be multiline. If it is empty, a comment with the regex is written.
+	Comment string
+}
+
+// GenerateFile returns the formatted Go file, that contains a variable
+// with the compiled regex. The output depends only on the arguments,
+// so it can be used in `//go:generate`.
+func GenerateFile(regex string, opts FileOptions) ([]byte, error) {
+	return New().File(regex, opts)
+}
+
+// File returns the formatted Go file, see GenerateFile.
+func (g Generator) File(regex string, opts FileOptions) ([]byte, error) {
+	return g.FileVars(opts.Package, []FileVar{{
+		Name:    opts.Name,
+		Regex:   regex,
+		Comment: opts.Comment,
+	}})
+}
+
+// FileVar is a variable of the file, that is generated by FileVars.
+type FileVar struct {
+	// Name is the name of the variable with the compiled regex.
+	Name string
+	// Regex is the regular expression.
+	Regex string
+	// Comment is the doc comment of the variable, see FileOptions.
+	Comment string
+}
+
+// FileVars returns the formatted Go file, that contains variables with
+// compiled regexes in the given order. Names of variables should be
+// unique.
+func (g Generator) FileVars(pkg string, vars []FileVar) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("package %q: %w", pkg, ErrInvalidIdentifier)
+	}
+
+	names := make(map[string]bool, len(vars))
+
+	for _, v := range vars {
+		if !token.IsIdentifier(v.Name) {
+			return nil, fmt.Errorf("variable %q: %w", v.Name, ErrInvalidIdentifier)
+		}
+
+		if names[v.Name] {
+			return nil, fmt.Errorf("variable %q is duplicated: %w", v.Name, ErrInvalidIdentifier)
+		}
+
+		names[v.Name] = true
+	}
+
+	var strBuilder strings.Builder
+
+	_, _ = strBuilder.WriteString("// Code generated by rex generator. DO NOT EDIT.\n\n")
+	_, _ = strBuilder.WriteString("package " + pkg + "\n\n")
+	_, _ = strBuilder.WriteString("import \"github.com/hedhyw/rex/pkg/rex\"\n")
+
+	for _, v := range vars {
+		code, err := g.Code(v.Regex)
+		if err != nil {
+			return nil, err
+		}
+
+		comment := v.Comment
+		if comment == "" {
+			comment = fmt.Sprintf("%s matches the regular expression:\n\n\t%s", v.Name, v.Regex)
+		}
+
+		_, _ = strBuilder.WriteString("\n" + commentLines(comment))
+		_, _ = strBuilder.WriteString("var " + v.Name + " = " + code + ".MustCompile()\n")
+	}
+
+	src, err := format.Source([]byte(strBuilder.String()))
+	if err != nil {
+		return nil, fmt.Errorf("formatting: %w", err)
+	}
+
+	return src, nil
+}
+
+// commentLines prefixes each line of the text with slashes.
+func commentLines(text string) string {
+	var strBuilder strings.Builder
+
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			_, _ = strBuilder.WriteString("//\n")
+
+			continue
+		}
+
+		_, _ = strBuilder.WriteString("// " + line + "\n")
+	}
+
+	return strBuilder.String()
+}
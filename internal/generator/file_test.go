@@ -0,0 +1,145 @@
+package generator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/generator"
+)
+
+func TestGenerateFile(t *testing.T) {
+	t.Parallel()
+
+	actual, err := generator.GenerateFile(`^\d+$`, generator.FileOptions{
+		Package: "example",
+		Name:    "NumberRegExp",
+		Comment: "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "// Code generated by rex generator. DO NOT EDIT.\n" +
+		"\n" +
+		"package example\n" +
+		"\n" +
+		"import \"github.com/hedhyw/rex/pkg/rex\"\n" +
+		"\n" +
+		"// NumberRegExp matches the regular expression:\n" +
+		"//\n" +
+		"//\t^\\d+$\n" +
+		"var NumberRegExp = rex.New(\n" +
+		"\trex.Chars.Begin(),\n" +
+		"\trex.Chars.Digits().Repeat().OneOrMore(),\n" +
+		"\trex.Chars.End(),\n" +
+		").MustCompile()\n"
+
+	if string(actual) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestGenerateFileComment(t *testing.T) {
+	t.Parallel()
+
+	actual, err := generator.GenerateFile(`a`, generator.FileOptions{
+		Package: "main",
+		Name:    "aRegExp",
+		Comment: "aRegExp matches a.\n\nIt is an example.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "// Code generated by rex generator. DO NOT EDIT.\n" +
+		"\n" +
+		"package main\n" +
+		"\n" +
+		"import \"github.com/hedhyw/rex/pkg/rex\"\n" +
+		"\n" +
+		"// aRegExp matches a.\n" +
+		"//\n" +
+		"// It is an example.\n" +
+		"var aRegExp = rex.New(\n" +
+		"\trex.Chars.Single('a'),\n" +
+		").MustCompile()\n"
+
+	if string(actual) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestGenerateFileVars(t *testing.T) {
+	t.Parallel()
+
+	actual, err := generator.New().FileVars("example", []generator.FileVar{{
+		Name:    "aRegExp",
+		Regex:   `a`,
+		Comment: "",
+	}, {
+		Name:    "bRegExp",
+		Regex:   `b`,
+		Comment: "bRegExp matches b.",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "// Code generated by rex generator. DO NOT EDIT.\n" +
+		"\n" +
+		"package example\n" +
+		"\n" +
+		"import \"github.com/hedhyw/rex/pkg/rex\"\n" +
+		"\n" +
+		"// aRegExp matches the regular expression:\n" +
+		"//\n" +
+		"//\ta\n" +
+		"var aRegExp = rex.New(\n" +
+		"\trex.Chars.Single('a'),\n" +
+		").MustCompile()\n" +
+		"\n" +
+		"// bRegExp matches b.\n" +
+		"var bRegExp = rex.New(\n" +
+		"\trex.Chars.Single('b'),\n" +
+		").MustCompile()\n"
+
+	if string(actual) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+
+	_, err = generator.New().FileVars("example", []generator.FileVar{
+		{Name: "re", Regex: `a`, Comment: ""},
+		{Name: "re", Regex: `b`, Comment: ""},
+	})
+	if !errors.Is(err, generator.ErrInvalidIdentifier) {
+		t.Fatalf("Expected: %v, got: %v", generator.ErrInvalidIdentifier, err)
+	}
+}
+
+func TestGenerateFileInvalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		opts generator.FileOptions
+	}{{
+		name: "package",
+		opts: generator.FileOptions{Package: "my-package", Name: "re", Comment: ""},
+	}, {
+		name: "variable",
+		opts: generator.FileOptions{Package: "main", Name: "", Comment: ""},
+	}}
+
+	for _, testCaseNotInParallel := range testCases {
+		testCase := testCaseNotInParallel
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := generator.GenerateFile(`a`, testCase.opts)
+			if !errors.Is(err, generator.ErrInvalidIdentifier) {
+				t.Fatalf("Expected: %v, got: %v", generator.ErrInvalidIdentifier, err)
+			}
+		})
+	}
+}
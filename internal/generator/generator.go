@@ -1,89 +1,443 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"regexp/syntax"
 	"strings"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
 )
 
-// GenerateCode returns rex code for a given regex.
+// ErrNotEquivalent is returned by Generator.Verify if the generated
+// code doesn't build the same regex.
+var ErrNotEquivalent = errors.New("generated code is not equivalent")
+
+// Generator converts regular expressions to rex code.
+type Generator struct {
+	// Syntax are flags, that are used to parse regular expressions:
+	// syntax.Perl or syntax.POSIX.
+	Syntax syntax.Flags
+	// Indent is written for each level of nesting in the code.
+	Indent string
+}
+
+// New returns the generator, that parses Perl syntax and indents the
+// code by tabs.
+func New() Generator {
+	return Generator{
+		Syntax: syntax.Perl,
+		Indent: "\t",
+	}
+}
+
+// GenerateCode returns rex code for a given regex. Constructions are
+// mapped to idiomatic rex calls, only unknown ones are kept raw. Parts,
+// that are equal to the output of helpers, are replaced by helpers.
 func GenerateCode(regex string) (generatedCode string, err error) {
-	regExpr, err := syntax.Parse(regex, syntax.Perl)
+	return New().Code(regex)
+}
+
+// GenerateTokens returns tokens, that are built by the code returned
+// from GenerateCode. It helps to check that the code is equivalent
+// to the regex.
+func GenerateTokens(regex string) ([]dialect.Token, error) {
+	return New().Tokens(regex)
+}
+
+// Code returns rex code for a given regex, see GenerateCode.
+func (g Generator) Code(regex string) (generatedCode string, err error) {
+	root, err := g.parse(regex)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse regexp: %w", err)
+		return "", err
 	}
 
 	var strBuilder strings.Builder
 
 	strBuilder.Grow(len(regex))
 	_, _ = strBuilder.WriteString("rex.New(\n")
-	writeRegexp(&strBuilder, regExpr, 1)
+
+	for _, n := range root {
+		n.write(&strBuilder, g.Indent, 1)
+	}
+
 	_, _ = strBuilder.WriteString(")")
 
 	return strBuilder.String(), nil
 }
 
-func writeRegexp(w io.StringWriter, regExpr *syntax.Regexp, indent int) {
-	//nolint: exhaustive // All cases captured in default.
+// Tokens returns tokens, that are built by the code, see GenerateTokens.
+func (g Generator) Tokens(regex string) ([]dialect.Token, error) {
+	root, err := g.parse(regex)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokensOf(root), nil
+}
+
+// Verify checks that the generated code builds a regex, that is
+// structurally equal to the given one. Otherwise it returns
+// ErrNotEquivalent.
+func (g Generator) Verify(regex string) error {
+	tokens, err := g.Tokens(regex)
+	if err != nil {
+		return err
+	}
+
+	regExpr, err := syntax.Parse(regex, g.Syntax)
+	if err != nil {
+		return fmt.Errorf("failed to parse regexp: %w", err)
+	}
+
+	re := rex.New(tokens...)
+	if err = re.Err(); err != nil {
+		return err
+	}
+
+	generated, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("failed to parse generated regexp: %w", err)
+	}
+
+	expected := regExpr.Simplify().String()
+	if actual := generated.Simplify().String(); actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrNotEquivalent, expected, actual)
+	}
+
+	return nil
+}
+
+func (g Generator) parse(regex string) ([]*node, error) {
+	regExpr, err := syntax.Parse(regex, g.Syntax)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse regexp: %w", err)
+	}
+
+	return convertSequence(regExpr), nil
+}
+
+// node is a call of rex, that builds the token. Children are arguments
+// of the call, they are written between head and tail.
+type node struct {
+	head     string
+	children []*node
+	tail     string
+	// inline nodes are written in one line.
+	inline bool
+
+	token dialect.Token
+}
+
+func leaf(code string, token dialect.Token) *node {
+	return &node{
+		head:     code,
+		children: nil,
+		tail:     "",
+		inline:   true,
+		token:    token,
+	}
+}
+
+func call(head string, children []*node, tail string, token dialect.Token) *node {
+	inline := len(children) == 1 && len(children[0].children) == 0
+
+	return &node{
+		head:     head,
+		children: children,
+		tail:     tail,
+		inline:   inline,
+		token:    token,
+	}
+}
+
+// group creates a call, that is always written in multiple lines.
+func group(head string, children []*node, tail string, token dialect.Token) *node {
+	n := call(head, children, tail, token)
+	n.inline = false
+
+	return n
+}
+
+func (n *node) write(w io.StringWriter, indentUnit string, indent int) {
+	strIndent := strings.Repeat(indentUnit, indent)
+
+	if n.inline {
+		_, _ = w.WriteString(strIndent + n.String() + ",\n")
+
+		return
+	}
+
+	_, _ = w.WriteString(strIndent + n.head + "\n")
+
+	for _, child := range n.children {
+		child.write(w, indentUnit, indent+1)
+	}
+
+	_, _ = w.WriteString(strIndent + n.tail + ",\n")
+}
+
+// String returns the code in one line.
+func (n *node) String() string {
+	args := make([]string, 0, len(n.children))
+	for _, child := range n.children {
+		args = append(args, child.String())
+	}
+
+	return n.head + strings.Join(args, ", ") + n.tail
+}
+
+func tokensOf(nodes []*node) []dialect.Token {
+	tokens := make([]dialect.Token, 0, len(nodes))
+	for _, n := range nodes {
+		tokens = append(tokens, n.token)
+	}
+
+	return tokens
+}
+
+// convertSequence converts the expression to nodes, that are matched
+// one after another.
+func convertSequence(regExpr *syntax.Regexp) []*node {
+	//nolint: exhaustive // Other cases are single nodes.
 	switch regExpr.Op {
+	case syntax.OpEmptyMatch:
+		return nil
 	case syntax.OpConcat:
-		writeConcat(w, regExpr, indent)
-	case syntax.OpCapture:
-		writeCapture(w, regExpr, indent)
+		if n, ok := convertHelper(regExpr); ok {
+			return []*node{n}
+		}
+
+		nodes := make([]*node, 0, len(regExpr.Sub))
+
+		for i := 0; i < len(regExpr.Sub); i++ {
+			if n, consumed := convertHelperPrefix(regExpr.Sub[i:]); consumed > 0 {
+				nodes = append(nodes, n)
+				i += consumed - 1
+
+				continue
+			}
+
+			if i > 0 {
+				if n, consumed := convertNumberRun(regExpr.Sub[i-1], regExpr.Sub[i:]); consumed > 0 {
+					nodes = append(nodes, n)
+					i += consumed - 1
+
+					continue
+				}
+			}
+
+			nodes = append(nodes, convertSequence(regExpr.Sub[i])...)
+		}
+
+		return nodes
 	default:
-		writeRaw(w, []*syntax.Regexp{regExpr}, indent)
+		return []*node{convert(regExpr)}
 	}
 }
 
-func writeCapture(w io.StringWriter, regExpr *syntax.Regexp, indent int) {
-	strIndent := strings.Repeat("\t", indent)
+// convertSingle converts the expression to exactly one node.
+func convertSingle(regExpr *syntax.Regexp) *node {
+	nodes := convertSequence(regExpr)
+
+	switch len(nodes) {
+	case 0:
+		return leaf(`rex.Common.Text("")`, rex.Common.Text(""))
+	case 1:
+		return nodes[0]
+	default:
+		return group("rex.Group.NonCaptured(", nodes, ")", rex.Group.NonCaptured(tokensOf(nodes)...))
+	}
+}
+
+// nolint: cyclop // Mapping of operations.
+func convert(regExpr *syntax.Regexp) *node {
+	if n, ok := convertHelper(regExpr); ok {
+		return n
+	}
+
+	//nolint: exhaustive // All cases captured in default.
+	switch regExpr.Op {
+	case syntax.OpLiteral:
+		return convertLiteral(regExpr)
+	case syntax.OpCharClass:
+		if regExpr.Flags&syntax.FoldCase != 0 {
+			if n, ok := convertFoldedClass(regExpr.Rune); ok {
+				return n
+			}
+		}
+
+		return convertClass(regExpr.Rune)
+	case syntax.OpAnyCharNotNL:
+		return leaf("rex.Chars.Any()", rex.Chars.Any())
+	case syntax.OpAnyChar:
+		return call(
+			"rex.Flags.AnyIncludeNewLine().Group(", []*node{leaf("rex.Chars.Any()", rex.Chars.Any())}, ")",
+			rex.Flags.AnyIncludeNewLine().Group(rex.Chars.Any()),
+		)
+	case syntax.OpBeginLine:
+		return call(
+			"rex.Flags.Multiline().Group(", []*node{leaf("rex.Chars.Begin()", rex.Chars.Begin())}, ")",
+			rex.Flags.Multiline().Group(rex.Chars.Begin()),
+		)
+	case syntax.OpEndLine:
+		return call(
+			"rex.Flags.Multiline().Group(", []*node{leaf("rex.Chars.End()", rex.Chars.End())}, ")",
+			rex.Flags.Multiline().Group(rex.Chars.End()),
+		)
+	case syntax.OpBeginText:
+		return leaf("rex.Chars.Begin()", rex.Chars.Begin())
+	case syntax.OpEndText:
+		if regExpr.Flags&syntax.WasDollar != 0 {
+			return leaf("rex.Chars.End()", rex.Chars.End())
+		}
+
+		return leaf("rex.Chars.EndOfText()", rex.Chars.EndOfText())
+	case syntax.OpWordBoundary:
+		return leaf("rex.Chars.ASCIIWordBoundary()", rex.Chars.ASCIIWordBoundary())
+	case syntax.OpNoWordBoundary:
+		return leaf("rex.Chars.NotASCIIWordBoundary()", rex.Chars.NotASCIIWordBoundary())
+	case syntax.OpCapture:
+		return convertCapture(regExpr)
+	case syntax.OpAlternate:
+		return convertAlternate(regExpr)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return convertRepeat(regExpr)
+	case syntax.OpConcat, syntax.OpEmptyMatch:
+		return convertSingle(regExpr)
+	default:
+		return leaf(fmt.Sprintf("rex.Common.Raw(%s)", quote(regExpr.String())), rex.Common.Raw(regExpr.String()))
+	}
+}
 
-	_, _ = w.WriteString(strIndent + "rex.Group.Define(\n")
+func convertLiteral(regExpr *syntax.Regexp) *node {
+	foldCase := regExpr.Flags&syntax.FoldCase != 0
 
-	if regExpr.Sub[0].Op != syntax.OpEmptyMatch {
-		writeRegexp(w, regExpr.Sub[0], indent+1)
+	text := string(regExpr.Rune)
+	if foldCase {
+		// The parser keeps upper case letters, but lower case is easier
+		// to read. Both are equivalent if the case is ignored.
+		text = strings.ToLower(text)
 	}
 
-	if regExpr.Name != "" {
-		_, _ = w.WriteString(fmt.Sprintf("%s).WithName(%q),\n", strIndent, regExpr.Name))
+	var n *node
+
+	if runes := []rune(text); len(runes) == 1 {
+		n = leaf(fmt.Sprintf("rex.Chars.Single(%q)", runes[0]), rex.Chars.Single(runes[0]))
 	} else {
-		_, _ = w.WriteString(strIndent + "),\n")
+		n = leaf(fmt.Sprintf("rex.Common.Text(%q)", text), rex.Common.Text(text))
+	}
+
+	if !foldCase {
+		return n
 	}
+
+	return call(
+		"rex.Flags.CaseInsensitive().Group(", []*node{n}, ")",
+		rex.Flags.CaseInsensitive().Group(n.token),
+	)
 }
 
-func writeConcat(w io.StringWriter, regExpr *syntax.Regexp, indent int) {
-	rawExprs := make([]*syntax.Regexp, 0, len(regExpr.Sub))
+func convertCapture(regExpr *syntax.Regexp) *node {
+	children := convertSequence(regExpr.Sub[0])
+	if len(children) == 0 {
+		// The group should be written even if it is empty.
+		children = []*node{leaf(`rex.Common.Text("")`, rex.Common.Text(""))}
+	}
 
-	for _, sub := range regExpr.Sub {
-		//nolint: exhaustive // All cases captured in default.
-		switch sub.Op {
-		case syntax.OpCapture:
-			writeRaw(w, rawExprs, indent)
+	token := rex.Group.Define(tokensOf(children)...)
+
+	if regExpr.Name == "" {
+		return group("rex.Group.Define(", children, ")", token)
+	}
 
-			rawExprs = rawExprs[:0]
+	return group(
+		"rex.Group.Define(", children, fmt.Sprintf(").WithName(%q)", regExpr.Name),
+		token.WithName(regExpr.Name),
+	)
+}
 
-			writeRegexp(w, sub, indent)
-		default:
-			rawExprs = append(rawExprs, sub)
-		}
+func convertAlternate(regExpr *syntax.Regexp) *node {
+	children := make([]*node, 0, len(regExpr.Sub))
+	for _, sub := range regExpr.Sub {
+		children = append(children, convertSingle(sub))
 	}
 
-	writeRaw(w, rawExprs, indent)
+	return group(
+		"rex.Group.Composite(", children, ").NonCaptured()",
+		rex.Group.Composite(tokensOf(children)...).NonCaptured(),
+	)
 }
 
-func writeRaw(w io.StringWriter, regExprs []*syntax.Regexp, indent int) {
-	if len(regExprs) == 0 {
-		return
+// repeater is implemented by tokens with the method Repeat.
+type repeater interface {
+	Repeat() base.Repetition
+}
+
+func convertRepeat(regExpr *syntax.Regexp) *node {
+	sub := convertSingle(regExpr.Sub[0])
+	preferFewer := regExpr.Flags&syntax.NonGreedy != 0
+
+	var (
+		method     string
+		repetition base.Repetition
+		token      dialect.Token
+	)
+
+	// Methods of repetitions return dialect.Token, so repeated tokens
+	// are repeated again by rex.Repeat.
+	_, repeated := sub.token.(base.Repetition)
+
+	if rep, ok := sub.token.(repeater); ok && !repeated {
+		repetition = rep.Repeat()
+		sub.tail += ".Repeat()"
+	} else {
+		repetition = rex.Repeat(sub.token)
+		sub = call("rex.Repeat(", []*node{sub}, ")", sub.token)
 	}
 
-	strIndent := strings.Repeat("\t", indent)
+	switch {
+	case regExpr.Op == syntax.OpStar && preferFewer:
+		method, token = "ZeroOrMorePreferFewer()", repetition.ZeroOrMorePreferFewer()
+	case regExpr.Op == syntax.OpStar:
+		method, token = "ZeroOrMore()", repetition.ZeroOrMore()
+	case regExpr.Op == syntax.OpPlus && preferFewer:
+		method, token = "OneOrMorePreferFewer()", repetition.OneOrMorePreferFewer()
+	case regExpr.Op == syntax.OpPlus:
+		method, token = "OneOrMore()", repetition.OneOrMore()
+	case regExpr.Op == syntax.OpQuest && preferFewer:
+		method, token = "ZeroOrOnePreferZero()", repetition.ZeroOrOnePreferZero()
+	case regExpr.Op == syntax.OpQuest:
+		method, token = "ZeroOrOne()", repetition.ZeroOrOne()
+	case regExpr.Min == regExpr.Max:
+		method, token = fmt.Sprintf("Exactly(%d)", regExpr.Min), repetition.Exactly(regExpr.Min)
+	case regExpr.Max == -1 && preferFewer:
+		method = fmt.Sprintf("EqualOrMoreThanPreferFewer(%d)", regExpr.Min)
+		token = repetition.EqualOrMoreThanPreferFewer(regExpr.Min)
+	case regExpr.Max == -1:
+		method, token = fmt.Sprintf("EqualOrMoreThan(%d)", regExpr.Min), repetition.EqualOrMoreThan(regExpr.Min)
+	case preferFewer:
+		method = fmt.Sprintf("BetweenPreferFewer(%d, %d)", regExpr.Min, regExpr.Max)
+		token = repetition.BetweenPreferFewer(regExpr.Min, regExpr.Max)
+	default:
+		method = fmt.Sprintf("Between(%d, %d)", regExpr.Min, regExpr.Max)
+		token = repetition.Between(regExpr.Min, regExpr.Max)
+	}
+
+	sub.tail += "." + method
+	sub.token = token
 
-	_, _ = w.WriteString(strIndent + "rex.Common.Raw(`")
+	return sub
+}
 
-	for _, re := range regExprs {
-		_, _ = w.WriteString(re.String())
+// quote returns the Go string literal. Raw strings are preferred.
+func quote(value string) string {
+	if strings.Contains(value, "`") {
+		return fmt.Sprintf("%q", value)
 	}
 
-	_, _ = w.WriteString("`),\n")
+	return "`" + value + "`"
 }
@@ -1,9 +1,16 @@
 package generator_test
 
 import (
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hedhyw/rex/internal/generator"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/rex"
 )
 
 type generatorTestCase struct {
@@ -35,6 +42,28 @@ func TestGenerateCodeOK(t *testing.T) {
 	}
 }
 
+func TestGenerateCodeIndent(t *testing.T) {
+	t.Parallel()
+
+	gen := generator.New()
+	gen.Indent = "  "
+
+	actual, err := gen.Code(`(a)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "rex.New(\n" +
+		"  rex.Group.Define(\n" +
+		"    rex.Chars.Single('a'),\n" +
+		"  ),\n" +
+		")"
+
+	if actual != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
 func TestGenerateCodeInvalidRegexpr(t *testing.T) {
 	t.Parallel()
 
@@ -44,41 +73,232 @@ func TestGenerateCodeInvalidRegexpr(t *testing.T) {
 	}
 }
 
+func TestGenerateTokensRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	regexes := []string{
+		`a`,
+		`(?P<name>1234)`,
+		`(1|12|123)`,
+		`a((\d+)([a-z]+\())`,
+		`^[a-z0-9._%+-]+@\w+\.com$`,
+		`(?i)hello\s*?world`,
+		`(?i)[a-c]x`,
+		`(?i)[a-z]+`,
+		`(?i)\pL`,
+		`(?i)[^a-z]\W[k]`,
+		`[^a-c]{2,5}?`,
+		`(?:ab)+|c{3,}?`,
+		`x{3}y{2,}z{0,1}`,
+		`\p{Greek}+\PL`,
+		`(?m)^x$`,
+		`(?s).\b\B\z`,
+		`\A.\z`,
+		`[^\d]\D\S\W`,
+		`[[:alpha:]][[:^punct:]][[:xdigit:]]`,
+		`[\w\-]+[^\s\d,]`,
+		`(a|bc|)+?`,
+		`()`,
+		"`",
+		`[^\x00-\x{10FFFF}]`,
+		`^(?:0|[1-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])$`,
+		rex.New(rex.Helper.IPv4()).String() + `:\d+`,
+		`x(?:-(?:[7-9]|[1-9][0-9]|[1-2][0-9][0-9]|300))+`,
+	}
+
+	for _, regexNotInParallel := range regexes {
+		regex := regexNotInParallel
+
+		t.Run(regex, func(t *testing.T) {
+			t.Parallel()
+
+			if err := generator.New().Verify(regex); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGenerateTokensRoundTripPOSIX(t *testing.T) {
+	t.Parallel()
+
+	gen := generator.New()
+	gen.Syntax = syntax.POSIX
+
+	if err := gen.Verify(`^[[:digit:]]+(a|b)$`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateCodeHelpers(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		token dialect.Token
+		code  string
+	}{
+		{name: "ipv4", token: rex.Helper.IPv4(), code: "rex.Helper.IPv4()"},
+		{name: "ipv6", token: rex.Helper.IPv6(), code: "rex.Helper.IPv6()"},
+		{name: "email", token: rex.Helper.Email(), code: "rex.Helper.Email()"},
+		{name: "md5", token: rex.Helper.MD5Hex(), code: "rex.Helper.MD5Hex()"},
+		{name: "sha256", token: rex.Helper.SHA256Hex(), code: "rex.Helper.SHA256Hex()"},
+		{name: "phone", token: rex.Helper.Phone(), code: "rex.Helper.Phone()"},
+	}
+
+	for _, testCaseNotInParallel := range testCases {
+		testCase := testCaseNotInParallel
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			regex := "^" + rex.New(testCase.token).String() + "$"
+
+			actual, err := generator.GenerateCode(regex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := "rex.New(\n" +
+				"	rex.Chars.Begin(),\n" +
+				"	" + testCase.code + ",\n" +
+				"	rex.Chars.End(),\n" +
+				")"
+
+			if actual != expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateCodeNumberRange(t *testing.T) {
+	t.Parallel()
+
+	// The seed is fixed, so failures are reproducible.
+	random := rand.New(rand.NewSource(1))
+
+	// randomNumber returns numbers of different lengths.
+	randomNumber := func() int32 {
+		return int32(random.Int63n(int64(math.Pow10(1 + random.Intn(9)))))
+	}
+
+	bounds := [][2]int32{
+		{100, 999}, {10, 99}, {0, 2577}, {5, 5}, {-229, 2577}, {1479, 1581},
+		{0, 0}, {-7, -7}, {-1, 1}, {0, 255}, {-300, -7}, {1, math.MaxInt32},
+	}
+
+	for i := 0; i < 200; i++ {
+		from, to := randomNumber(), randomNumber()
+
+		switch i % 4 {
+		case 0:
+			// Negative to positive.
+			from = -from
+		case 1:
+			// Equal bounds.
+			to = from
+		case 2:
+			// The same count of digits.
+			to = from + int32(random.Int63n(int64(math.Pow10(len(strconv.Itoa(int(from)))))-int64(from)))
+		}
+
+		bounds = append(bounds, [2]int32{from, to})
+	}
+
+	for _, b := range bounds {
+		from, to := b[0], b[1]
+		if from > to {
+			from, to = to, from
+		}
+
+		regex := "^" + rex.New(rex.Helper.NumberRange(from, to)).String() + "$"
+
+		actual, err := generator.GenerateCode(regex)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "rex.New(\n" +
+			"	rex.Chars.Begin(),\n" +
+			"	rex.Helper.NumberRange(" + strconv.Itoa(int(from)) + ", " + strconv.Itoa(int(to)) + "),\n" +
+			"	rex.Chars.End(),\n" +
+			")"
+
+		if actual != expected {
+			t.Fatalf("%s:\nExpected:\n%s\nGot:\n%s", regex, expected, actual)
+		}
+	}
+}
+
+func TestGenerateCodeHelpersNotMatched(t *testing.T) {
+	t.Parallel()
+
+	// The order of alternatives differs from the helper output.
+	actual, err := generator.GenerateCode(`^(?:2[0-4][0-9]|25[0-5]|1[0-9][0-9]|[1-9][0-9]|[0-9])$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(actual, "rex.Helper") {
+		t.Errorf("Unexpected helper:\n%s", actual)
+	}
+}
+
 // nolint: funlen // test cases.
 func getSuccessGroupTestCases() []generatorTestCase {
 	return []generatorTestCase{{
 		name:  "one_letter_regex",
 		regex: `a`,
 		result: "rex.New(\n" +
-			"	rex.Common.Raw(`a`),\n" +
+			"	rex.Chars.Single('a'),\n" +
 			")",
 	}, {
 		name:  "uncaptured",
 		regex: `(?P<name>1234)`,
 		result: "rex.New(\n" +
 			"	rex.Group.Define(\n" +
-			"		rex.Common.Raw(`1234`),\n" +
+			"		rex.Common.Text(\"1234\"),\n" +
 			"	).WithName(\"name\"),\n" +
 			")",
+	}, {
+		name:  "empty_group",
+		regex: `()`,
+		result: "rex.New(\n" +
+			"	rex.Group.Define(\n" +
+			"		rex.Common.Text(\"\"),\n" +
+			"	),\n" +
+			")",
 	}, {
 		name:  "concat",
 		regex: `(1|12|123)`,
 		result: "rex.New(\n" +
 			"	rex.Group.Define(\n" +
-			"		rex.Common.Raw(`1(?:)|2(?:(?:)|3)`),\n" +
+			"		rex.Chars.Single('1'),\n" +
+			"		rex.Group.Composite(\n" +
+			"			rex.Common.Text(\"\"),\n" +
+			"			rex.Group.NonCaptured(\n" +
+			"				rex.Chars.Single('2'),\n" +
+			"				rex.Group.Composite(\n" +
+			"					rex.Common.Text(\"\"),\n" +
+			"					rex.Chars.Single('3'),\n" +
+			"				).NonCaptured(),\n" +
+			"			),\n" +
+			"		).NonCaptured(),\n" +
 			"	),\n" +
 			")",
 	}, {
 		name:  "simple regex",
 		regex: `a((\d+)([a-z]+\())`,
 		result: "rex.New(\n" +
-			"	rex.Common.Raw(`a`),\n" +
+			"	rex.Chars.Single('a'),\n" +
 			"	rex.Group.Define(\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[0-9]+`),\n" +
+			"			rex.Chars.Digits().Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[a-z]+\\(`),\n" +
+			"			rex.Chars.Range('a', 'z').Repeat().OneOrMore(),\n" +
+			"			rex.Chars.Single('('),\n" +
 			"		),\n" +
 			"	),\n" +
 			")",
@@ -89,31 +309,154 @@ func getSuccessGroupTestCases() []generatorTestCase {
 		result: "rex.New(\n" +
 			"	rex.Group.Define(\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[0-9]+`),\n" +
+			"			rex.Chars.Digits().Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[a-z]+`),\n" +
+			"			rex.Chars.Range('a', 'z').Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"	),\n" +
-			"	rex.Common.Raw(`a`),\n" +
+			"	rex.Chars.Single('a'),\n" +
 			"	rex.Group.Define(\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[0-9]+`),\n" +
+			"			rex.Chars.Digits().Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[a-z]+`),\n" +
+			"			rex.Chars.Range('a', 'z').Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"	),\n" +
-			"	rex.Common.Raw(`a`),\n" +
+			"	rex.Chars.Single('a'),\n" +
 			"	rex.Group.Define(\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[0-9]+`),\n" +
+			"			rex.Chars.Digits().Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"		rex.Group.Define(\n" +
-			"			rex.Common.Raw(`[a-z]+`),\n" +
+			"			rex.Chars.Range('a', 'z').Repeat().OneOrMore(),\n" +
 			"		),\n" +
 			"	),\n" +
-			"	rex.Common.Raw(`a`),\n" +
+			"	rex.Chars.Single('a'),\n" +
+			")",
+	}, {
+		name:  "email",
+		regex: `^[\w.+-]+@\w+\.com$`,
+		result: "rex.New(\n" +
+			"	rex.Chars.Begin(),\n" +
+			"	rex.Common.Class(\n" +
+			"		rex.Chars.WordCharacter(),\n" +
+			"		rex.Chars.Single('+'),\n" +
+			"		rex.Chars.Range('-', '.'),\n" +
+			"	).Repeat().OneOrMore(),\n" +
+			"	rex.Chars.Single('@'),\n" +
+			"	rex.Chars.WordCharacter().Repeat().OneOrMore(),\n" +
+			"	rex.Common.Text(\".com\"),\n" +
+			"	rex.Chars.End(),\n" +
+			")",
+	}, {
+		name:  "inline_class",
+		regex: `[\d,]`,
+		result: "rex.New(\n" +
+			"	rex.Common.Class(rex.Chars.Digits(), rex.Chars.Single(',')),\n" +
+			")",
+	}, {
+		name:  "named_class_parts",
+		regex: `[\p{Greek}\d]`,
+		result: "rex.New(\n" +
+			"	rex.Common.Class(rex.Chars.Digits(), rex.Chars.UnicodeByName(\"Greek\")),\n" +
+			")",
+	}, {
+		name:  "negated_named_class_parts",
+		regex: `[^[:punct:]\s]`,
+		result: "rex.New(\n" +
+			"	rex.Common.NotClass(rex.Chars.Whitespace(), rex.Chars.Punctuation()),\n" +
+			")",
+	}, {
+		name:  "case_insensitive",
+		regex: `(?i)hello\s*?`,
+		result: "rex.New(\n" +
+			"	rex.Flags.CaseInsensitive().Group(rex.Common.Text(\"hello\")),\n" +
+			"	rex.Chars.Whitespace().Repeat().ZeroOrMorePreferFewer(),\n" +
+			")",
+	}, {
+		name:  "case_insensitive_class",
+		regex: `(?i)[a-z]+\pL[^\w-]`,
+		result: "rex.New(\n" +
+			"	rex.Flags.CaseInsensitive().Group(rex.Chars.Range('a', 'z')).Repeat().OneOrMore(),\n" +
+			"	rex.Flags.CaseInsensitive().Group(rex.Chars.UnicodeByName(\"L\")),\n" +
+			"	rex.Flags.CaseInsensitive().Group(\n" +
+			"		rex.Common.NotClass(rex.Chars.WordCharacter(), rex.Chars.Single('-')),\n" +
+			"	),\n" +
+			")",
+	}, {
+		name:  "negated",
+		regex: `[^a-c]{2,5}?\PL`,
+		result: "rex.New(\n" +
+			"	rex.Chars.Range('a', 'c').Not().Repeat().BetweenPreferFewer(2, 5),\n" +
+			"	rex.Chars.UnicodeByName(\"L\").Not(),\n" +
+			")",
+	}, {
+		name:  "alternation",
+		regex: `(?:ab)+|c{3,}`,
+		result: "rex.New(\n" +
+			"	rex.Group.Composite(\n" +
+			"		rex.Repeat(rex.Common.Text(\"ab\")).OneOrMore(),\n" +
+			"		rex.Chars.Single('c').Repeat().EqualOrMoreThan(3),\n" +
+			"	).NonCaptured(),\n" +
+			")",
+	}, {
+		name:  "anchors",
+		regex: `(?m)^(?s:.)\b\B\z$`,
+		result: "rex.New(\n" +
+			"	rex.Flags.Multiline().Group(rex.Chars.Begin()),\n" +
+			"	rex.Flags.AnyIncludeNewLine().Group(rex.Chars.Any()),\n" +
+			"	rex.Chars.ASCIIWordBoundary(),\n" +
+			"	rex.Chars.NotASCIIWordBoundary(),\n" +
+			"	rex.Chars.EndOfText(),\n" +
+			"	rex.Flags.Multiline().Group(rex.Chars.End()),\n" +
+			")",
+	}, {
+		name:  "repeated_group",
+		regex: `(a|bc)?`,
+		result: "rex.New(\n" +
+			"	rex.Group.Define(\n" +
+			"		rex.Group.Composite(\n" +
+			"			rex.Chars.Single('a'),\n" +
+			"			rex.Common.Text(\"bc\"),\n" +
+			"		).NonCaptured(),\n" +
+			"	).Repeat().ZeroOrOne(),\n" +
+			")",
+	}, {
+		name:  "repeated_repetition",
+		regex: `(?:a+){2}`,
+		result: "rex.New(\n" +
+			"	rex.Repeat(rex.Chars.Single('a').Repeat().OneOrMore()).Exactly(2),\n" +
 			")",
 	}}
 }
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	gen := generator.New()
+	gen.Indent = "  "
+
+	actual, err := gen.Explain(`^(?P<user>[a-z]+)@(?:com|org){1,2}?\b(?i:x)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "beginning of the text\n" +
+		"group #1 \"user\" of:\n" +
+		"  one or more of:\n" +
+		"    one character of [a-z]\n" +
+		"text \"@\"\n" +
+		"between 1 and 2 (prefer fewer) of:\n" +
+		"  one of:\n" +
+		"    text \"com\"\n" +
+		"    text \"org\"\n" +
+		"ASCII word boundary\n" +
+		"text \"x\" ignoring case\n" +
+		"end of the text\n"
+
+	if actual != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
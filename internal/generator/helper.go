@@ -0,0 +1,487 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"strconv"
+	"sync"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// knownHelper is a helper with a fixed pattern.
+type knownHelper struct {
+	code  string
+	token dialect.Token
+
+	// key is the simplified pattern of the helper.
+	key string
+	// concatLen is the number of expressions in the parsed pattern, if it
+	// is a concatenation, otherwise it is 0.
+	concatLen int
+}
+
+// knownHelpers are computed on the first use.
+var knownHelpers struct {
+	once    sync.Once
+	helpers []knownHelper
+}
+
+func getKnownHelpers() []knownHelper {
+	knownHelpers.once.Do(func() {
+		helpers := []struct {
+			code  string
+			token dialect.Token
+		}{
+			{code: "rex.Helper.IP()", token: rex.Helper.IP()},
+			{code: "rex.Helper.IPv4()", token: rex.Helper.IPv4()},
+			{code: "rex.Helper.IPv6()", token: rex.Helper.IPv6()},
+			{code: "rex.Helper.Email()", token: rex.Helper.Email()},
+			{code: "rex.Helper.HostnameRFC952()", token: rex.Helper.HostnameRFC952()},
+			{code: "rex.Helper.HostnameRFC1123()", token: rex.Helper.HostnameRFC1123()},
+			{code: "rex.Helper.MD5Hex()", token: rex.Helper.MD5Hex()},
+			{code: "rex.Helper.SHA1Hex()", token: rex.Helper.SHA1Hex()},
+			{code: "rex.Helper.SHA256Hex()", token: rex.Helper.SHA256Hex()},
+			{code: "rex.Helper.Phone()", token: rex.Helper.Phone()},
+			{code: "rex.Helper.PhoneE164()", token: rex.Helper.PhoneE164()},
+			{code: "rex.Helper.PhoneE123()", token: rex.Helper.PhoneE123()},
+			{code: "rex.Helper.PhoneNationalE123()", token: rex.Helper.PhoneNationalE123()},
+			{code: "rex.Helper.PhoneInternationalE123()", token: rex.Helper.PhoneInternationalE123()},
+		}
+
+		for _, helper := range helpers {
+			regExpr, err := syntax.Parse(rex.New(helper.token).String(), syntax.Perl)
+			if err != nil {
+				// Helpers are always valid, it is checked by tests.
+				continue
+			}
+
+			concatLen := 0
+			if regExpr.Op == syntax.OpConcat {
+				concatLen = len(regExpr.Sub)
+			}
+
+			knownHelpers.helpers = append(knownHelpers.helpers, knownHelper{
+				code:      helper.code,
+				token:     helper.token,
+				key:       simplifiedKey(regExpr),
+				concatLen: concatLen,
+			})
+		}
+	})
+
+	return knownHelpers.helpers
+}
+
+// simplifiedKey returns the pattern, that is the same for structurally
+// equal expressions.
+func simplifiedKey(regExpr *syntax.Regexp) string {
+	return regExpr.Simplify().String()
+}
+
+// convertHelper returns the helper call if the expression is equal to
+// the helper output.
+func convertHelper(regExpr *syntax.Regexp) (*node, bool) {
+	key := simplifiedKey(regExpr)
+
+	for _, helper := range getKnownHelpers() {
+		if helper.key == key {
+			return leaf(helper.code, helper.token), true
+		}
+	}
+
+	return convertNumberRange(regExpr, key)
+}
+
+// convertHelperPrefix checks if first expressions of the concatenation
+// are equal to the helper output. It returns the helper call and the
+// number of expressions, that it replaces.
+func convertHelperPrefix(regExprs []*syntax.Regexp) (*node, int) {
+	keys := make(map[int]string)
+
+	keyOf := func(n int) string {
+		if key, ok := keys[n]; ok {
+			return key
+		}
+
+		key := simplifiedKey(&syntax.Regexp{Op: syntax.OpConcat, Sub: regExprs[:n]})
+		keys[n] = key
+
+		return key
+	}
+
+	for _, helper := range getKnownHelpers() {
+		if helper.concatLen < 2 || helper.concatLen > len(regExprs) {
+			continue
+		}
+
+		if keyOf(helper.concatLen) == helper.key {
+			return leaf(helper.code, helper.token), helper.concatLen
+		}
+	}
+
+	// Negative number ranges: `-(?:...)`.
+	if len(regExprs) >= 2 && isMinus(regExprs[0]) && regExprs[1].Op == syntax.OpAlternate {
+		n, ok := convertNumberRange(&syntax.Regexp{Op: syntax.OpConcat, Sub: regExprs[:2]}, keyOf(2))
+		if ok {
+			return n, 2
+		}
+	}
+
+	return nil, 0
+}
+
+// convertNumberRange infers arguments of Helper.NumberRange, and checks
+// that the helper output is equal to the expression. Only alternations
+// are checked here, other numbers are checked if they are delimited by
+// anchors, see convertNumberRun.
+func convertNumberRange(regExpr *syntax.Regexp, key string) (*node, bool) {
+	if !isNumberAlternation(regExpr) {
+		return nil, false
+	}
+
+	return numberRangeOf(regExpr, key)
+}
+
+// convertNumberRun checks if expressions between the delimiter and the
+// next delimiter, like `^` and `$` or `\b`, are equal to the output of
+// Helper.NumberRange. The parser merges common prefixes of alternatives,
+// and concatenations of the helper output are merged with outer ones,
+// so the number is found by bounds of all expressions between delimiters.
+// It returns the helper call and the number of expressions, that it
+// replaces.
+func convertNumberRun(prev *syntax.Regexp, regExprs []*syntax.Regexp) (*node, int) {
+	if !isNumberStart(prev) {
+		return nil, 0
+	}
+
+	for i, regExpr := range regExprs {
+		if !isNumberEnd(regExpr) {
+			continue
+		}
+
+		run := &syntax.Regexp{Op: syntax.OpConcat, Sub: regExprs[:i]}
+
+		switch i {
+		case 0:
+			return nil, 0
+		case 1:
+			run = regExprs[0]
+		}
+
+		if n, ok := numberRangeOf(run, simplifiedKey(run)); ok {
+			return n, i
+		}
+
+		return nil, 0
+	}
+
+	return nil, 0
+}
+
+// numberRangeOf returns the call of Helper.NumberRange with bounds of
+// the expression, if its output is equal to the expression.
+func numberRangeOf(regExpr *syntax.Regexp, key string) (*node, bool) {
+	from, to, ok := numberBounds(regExpr)
+	if !ok || from < math.MinInt32 || to > math.MaxInt32 {
+		return nil, false
+	}
+
+	token := rex.Helper.NumberRange(int32(from), int32(to))
+
+	helperExpr, err := syntax.Parse(rex.New(token).String(), syntax.Perl)
+	if err != nil || simplifiedKey(helperExpr) != key {
+		return nil, false
+	}
+
+	return leaf(fmt.Sprintf("rex.Helper.NumberRange(%d, %d)", from, to), token), true
+}
+
+// maxNumberDigits is the maximum length of numbers, that are checked.
+// Bigger numbers are not supported by Helper.NumberRange.
+const maxNumberDigits = 10
+
+// numberBounds returns the minimum and the maximum numbers, that are
+// matched by the expression.
+func numberBounds(regExpr *syntax.Regexp) (from int64, to int64, ok bool) {
+	if rest, ok := trimMinus(regExpr); ok {
+		from, to, ok = numberBounds(rest)
+
+		return -to, -from, ok
+	}
+
+	//nolint: exhaustive // Other expressions are checked by digits.
+	switch regExpr.Op {
+	case syntax.OpCapture:
+		return numberBounds(regExpr.Sub[0])
+	case syntax.OpAlternate:
+		from, to = math.MaxInt64, math.MinInt64
+
+		for _, sub := range regExpr.Sub {
+			subFrom, subTo, ok := numberBounds(sub)
+			if !ok {
+				return 0, 0, false
+			}
+
+			if subFrom < from {
+				from = subFrom
+			}
+
+			if subTo > to {
+				to = subTo
+			}
+		}
+
+		return from, to, true
+	}
+
+	bounds, ok := digitsBounds(regExpr)
+	if !ok {
+		return 0, 0, false
+	}
+
+	minLength, maxLength := maxNumberDigits+1, 0
+
+	for length := range bounds {
+		if length < minLength {
+			minLength = length
+		}
+
+		if length > maxLength {
+			maxLength = length
+		}
+	}
+
+	if minLength == 0 {
+		return 0, 0, false
+	}
+
+	from, errFrom := strconv.ParseInt(bounds[minLength].min, 10, 64)
+	to, errTo := strconv.ParseInt(bounds[maxLength].max, 10, 64)
+
+	return from, to, errFrom == nil && errTo == nil
+}
+
+// trimMinus returns the expression without the leading minus.
+func trimMinus(regExpr *syntax.Regexp) (*syntax.Regexp, bool) {
+	switch {
+	case regExpr.Op == syntax.OpConcat && len(regExpr.Sub) > 1 && isMinus(regExpr.Sub[0]):
+		if len(regExpr.Sub) == 2 {
+			return regExpr.Sub[1], true
+		}
+
+		return &syntax.Regexp{Op: syntax.OpConcat, Sub: regExpr.Sub[1:]}, true
+	case regExpr.Op == syntax.OpConcat && len(regExpr.Sub) > 0:
+		// The minus is merged with following digits: `-1[0-9]`.
+		first, ok := trimMinus(regExpr.Sub[0])
+		if !ok {
+			return nil, false
+		}
+
+		sub := append([]*syntax.Regexp{first}, regExpr.Sub[1:]...)
+
+		return &syntax.Regexp{Op: syntax.OpConcat, Sub: sub}, true
+	case regExpr.Op == syntax.OpLiteral && len(regExpr.Rune) > 1 &&
+		regExpr.Rune[0] == '-' && regExpr.Flags&syntax.FoldCase == 0:
+		return &syntax.Regexp{Op: syntax.OpLiteral, Rune: regExpr.Rune[1:]}, true
+	default:
+		return nil, false
+	}
+}
+
+// textBounds are the lexicographically smallest and the biggest texts
+// of the same length.
+type textBounds struct {
+	min string
+	max string
+}
+
+// digitsBounds returns bounds of texts of digits, that are matched by
+// the expression, by their lengths. For texts of the same length
+// lexicographical order is the same as numerical order.
+func digitsBounds(regExpr *syntax.Regexp) (map[int]textBounds, bool) {
+	//nolint: exhaustive // Other expressions are not supported.
+	switch regExpr.Op {
+	case syntax.OpEmptyMatch:
+		return map[int]textBounds{0: {}}, true
+	case syntax.OpLiteral:
+		text := string(regExpr.Rune)
+		if regExpr.Flags&syntax.FoldCase != 0 || !isDigits(text) {
+			return nil, false
+		}
+
+		return map[int]textBounds{len(text): {min: text, max: text}}, true
+	case syntax.OpCharClass:
+		if len(regExpr.Rune) == 0 || regExpr.Rune[0] < '0' || regExpr.Rune[len(regExpr.Rune)-1] > '9' {
+			return nil, false
+		}
+
+		return map[int]textBounds{1: {
+			min: string(regExpr.Rune[0]),
+			max: string(regExpr.Rune[len(regExpr.Rune)-1]),
+		}}, true
+	case syntax.OpCapture:
+		return digitsBounds(regExpr.Sub[0])
+	case syntax.OpQuest:
+		bounds, ok := digitsBounds(regExpr.Sub[0])
+		if !ok {
+			return nil, false
+		}
+
+		return unionBounds(bounds, map[int]textBounds{0: {}}), true
+	case syntax.OpRepeat:
+		return repeatBounds(regExpr)
+	case syntax.OpConcat:
+		bounds := map[int]textBounds{0: {}}
+
+		for _, sub := range regExpr.Sub {
+			subBounds, ok := digitsBounds(sub)
+			if !ok {
+				return nil, false
+			}
+
+			bounds = concatBounds(bounds, subBounds)
+		}
+
+		return bounds, len(bounds) > 0
+	case syntax.OpAlternate:
+		var bounds map[int]textBounds
+
+		for _, sub := range regExpr.Sub {
+			subBounds, ok := digitsBounds(sub)
+			if !ok {
+				return nil, false
+			}
+
+			bounds = unionBounds(bounds, subBounds)
+		}
+
+		return bounds, len(bounds) > 0
+	default:
+		return nil, false
+	}
+}
+
+func repeatBounds(regExpr *syntax.Regexp) (map[int]textBounds, bool) {
+	if regExpr.Max < 0 || regExpr.Max > maxNumberDigits {
+		return nil, false
+	}
+
+	sub, ok := digitsBounds(regExpr.Sub[0])
+	if !ok {
+		return nil, false
+	}
+
+	var bounds map[int]textBounds
+
+	repeated := map[int]textBounds{0: {}}
+
+	for i := 0; i <= regExpr.Max; i++ {
+		if i >= regExpr.Min {
+			bounds = unionBounds(bounds, repeated)
+		}
+
+		repeated = concatBounds(repeated, sub)
+	}
+
+	return bounds, len(bounds) > 0
+}
+
+// concatBounds returns bounds of texts of the first expression, that
+// are followed by texts of the second one. Texts, that are longer than
+// maxNumberDigits, are ignored.
+func concatBounds(first, second map[int]textBounds) map[int]textBounds {
+	var bounds map[int]textBounds
+
+	for firstLength, firstBounds := range first {
+		for secondLength, secondBounds := range second {
+			if firstLength+secondLength > maxNumberDigits {
+				continue
+			}
+
+			bounds = unionBounds(bounds, map[int]textBounds{firstLength + secondLength: {
+				min: firstBounds.min + secondBounds.min,
+				max: firstBounds.max + secondBounds.max,
+			}})
+		}
+	}
+
+	return bounds
+}
+
+func unionBounds(first, second map[int]textBounds) map[int]textBounds {
+	bounds := make(map[int]textBounds, len(first)+len(second))
+
+	for length, b := range first {
+		bounds[length] = b
+	}
+
+	for length, b := range second {
+		current, ok := bounds[length]
+
+		if !ok || b.min < current.min {
+			current.min = b.min
+		}
+
+		if !ok || b.max > current.max {
+			current.max = b.max
+		}
+
+		bounds[length] = current
+	}
+
+	return bounds
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return text != ""
+}
+
+// isNumberAlternation checks if the expression is an alternation, that
+// is optionally captured or negated: `(-(?:...)|...)`.
+func isNumberAlternation(regExpr *syntax.Regexp) bool {
+	if regExpr.Op == syntax.OpCapture {
+		regExpr = regExpr.Sub[0]
+	}
+
+	if regExpr.Op == syntax.OpConcat && len(regExpr.Sub) == 2 && isMinus(regExpr.Sub[0]) {
+		regExpr = regExpr.Sub[1]
+	}
+
+	return regExpr.Op == syntax.OpAlternate
+}
+
+func isMinus(regExpr *syntax.Regexp) bool {
+	return regExpr.Op == syntax.OpLiteral && string(regExpr.Rune) == "-" &&
+		regExpr.Flags&syntax.FoldCase == 0
+}
+
+// isNumberStart checks if a number can begin after the expression.
+func isNumberStart(regExpr *syntax.Regexp) bool {
+	//nolint: exhaustive // Other expressions are not delimiters.
+	switch regExpr.Op {
+	case syntax.OpBeginText, syntax.OpBeginLine, syntax.OpWordBoundary:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNumberEnd checks if a number can end before the expression.
+func isNumberEnd(regExpr *syntax.Regexp) bool {
+	//nolint: exhaustive // Other expressions are not delimiters.
+	switch regExpr.Op {
+	case syntax.OpEndText, syntax.OpEndLine, syntax.OpWordBoundary:
+		return true
+	default:
+		return false
+	}
+}
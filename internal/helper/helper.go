@@ -1,7 +1,9 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hedhyw/rex/pkg/dialect"
 )
@@ -28,18 +30,103 @@ func ByteToken(val byte) dialect.Token {
 	})
 }
 
+// ErrorToken creates a token that writes nothing and reports the error.
+// It is used by tokens that received an invalid input.
+func ErrorToken(err error) dialect.Token {
+	return TokenFunc(func(dialect.StringByteWriter) (int, error) {
+		return 0, err
+	})
+}
+
 // ProcessTokens goes thought all tokens and call WriteTo method.
+// It doesn't stop on the first error, all errors are collected
+// and returned together.
 func ProcessTokens(w dialect.StringByteWriter, tokens []dialect.Token) (int, error) {
-	var totalWritten int
+	var (
+		totalWritten int
+		errs         Errors
+	)
 
 	for _, t := range tokens {
 		n, err := t.WriteTo(w)
 		if err != nil {
-			return n, err
+			errs = errs.append(err)
 		}
 
 		totalWritten += n
 	}
 
-	return totalWritten, nil
+	if len(errs) == 0 {
+		return totalWritten, nil
+	}
+
+	return totalWritten, errs
+}
+
+// Errors is a list of errors. It implements error interface.
+type Errors []error
+
+func (errs Errors) append(err error) Errors {
+	if nested, ok := err.(Errors); ok {
+		return append(errs, nested...)
+	}
+
+	return append(errs, err)
+}
+
+// Error implements error interface.
+func (errs Errors) Error() string {
+	messages := make([]string, 0, len(errs))
+
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the list of errors. It is used by errors.Is and
+// errors.As since Go 1.20.
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// Is reports whether any of errors matches the target. It allows to use
+// errors.Is before Go 1.20.
+func (errs Errors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error that matches the target. It allows to use
+// errors.As before Go 1.20.
+func (errs Errors) As(target any) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsWord checks that the text is not empty and contains only ASCII
+// word characters: `[0-9A-Za-z_]`.
+func IsWord(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for _, r := range text {
+		if r != '_' && !('0' <= r && r <= '9') && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+
+	return true
 }
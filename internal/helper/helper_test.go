@@ -2,6 +2,9 @@ package helper_test
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -124,3 +127,90 @@ func TestProcessTokensFailed(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestProcessTokensMultipleErrors(t *testing.T) {
+	t.Parallel()
+
+	// nolint: err113 // Test.
+	var (
+		errFirst  = errors.New("first")
+		errSecond = errors.New("second")
+	)
+
+	sb := new(strings.Builder)
+
+	n, err := helper.ProcessTokens(sb, []dialect.Token{
+		helper.ErrorToken(errFirst),
+		helper.ByteToken('a'),
+		helper.TokenFunc(func(w dialect.StringByteWriter) (int, error) {
+			return helper.ProcessTokens(w, []dialect.Token{
+				helper.ByteToken('b'),
+				helper.ErrorToken(errSecond),
+			})
+		}),
+	})
+
+	switch {
+	case !errors.Is(err, errFirst):
+		t.Fatalf("Actual: %v, Expected: %v", err, errFirst)
+	case !errors.Is(err, errSecond):
+		t.Fatalf("Actual: %v, Expected: %v", err, errSecond)
+	case err.Error() != "first\nsecond":
+		t.Fatalf("Actual: %q, Expected: %q", err.Error(), "first\nsecond")
+	case n != 2:
+		t.Fatalf("Actual: %d, Expected: %d", n, 2)
+	}
+
+	if actual := sb.String(); actual != "ab" {
+		t.Fatalf("Actual: %q, Expected: %q", actual, "ab")
+	}
+}
+
+func TestErrorsIsAs(t *testing.T) {
+	t.Parallel()
+
+	// nolint: err113 // Test.
+	var (
+		errFirst  = errors.New("first")
+		errSecond = errors.New("second")
+	)
+
+	errs := helper.Errors{errFirst, fmt.Errorf("wrapped: %w", &os.PathError{Op: "open", Path: "p", Err: errSecond})}
+
+	// Methods are called directly, because errors.Is and errors.As
+	// use Unwrap() []error since Go 1.20.
+	if !errs.Is(errFirst) || !errs.Is(errSecond) {
+		t.Fatalf("Expected %v to match both errors", errs)
+	}
+
+	if errs.Is(errors.New("first")) {
+		t.Fatal("Expected not to match another error")
+	}
+
+	var pathErr *os.PathError
+	if !errs.As(&pathErr) || pathErr.Path != "p" {
+		t.Fatalf("Expected to find *os.PathError, got %v", pathErr)
+	}
+
+	var numErr *strconv.NumError
+	if errs.As(&numErr) {
+		t.Fatal("Expected not to find *strconv.NumError")
+	}
+}
+
+func TestIsWord(t *testing.T) {
+	t.Parallel()
+
+	for text, expected := range map[string]bool{
+		"":        false,
+		"name":    true,
+		"Name_01": true,
+		"a.b":     false,
+		"a b":     false,
+		"имя":     false,
+	} {
+		if actual := helper.IsWord(text); actual != expected {
+			t.Errorf("%q: Actual: %v, Expected: %v", text, actual, expected)
+		}
+	}
+}
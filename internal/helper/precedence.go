@@ -0,0 +1,283 @@
+package helper
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// Precedence of operators. A token with lower precedence should be
+// grouped before it is used with an operator of higher precedence.
+type Precedence int
+
+// Precedences from the lowest to the highest.
+const (
+	PrecAlternate Precedence = iota
+	PrecConcat
+	PrecRepeat
+	PrecAtom
+)
+
+// PrecedenceOf returns the precedence of the top-level operator of the node.
+// Raw expressions are parsed just enough to find it.
+func PrecedenceOf(node *dialect.AST) Precedence {
+	switch node.Kind {
+	case dialect.KindRaw:
+		return rawPrecedence(node.Value)
+	case dialect.KindLiteral:
+		if utf8.RuneCountInString(node.Value) == 1 {
+			return PrecAtom
+		}
+
+		return PrecConcat
+	case dialect.KindConcat, dialect.KindAlternate:
+		if len(node.Sub) == 1 {
+			return PrecedenceOf(node.Sub[0])
+		}
+
+		if node.Kind == dialect.KindAlternate {
+			return PrecAlternate
+		}
+
+		return PrecConcat
+	case dialect.KindRepeat:
+		return PrecRepeat
+	case dialect.KindEmpty, dialect.KindFlags:
+		// They can't be repeated.
+		return PrecConcat
+	default:
+		return PrecAtom
+	}
+}
+
+// repeatCountRe matches bounds of a repetition: `{2}`, `{2,}` or `{2,3}`.
+var repeatCountRe = regexp.MustCompile(`^\{[0-9]+(,[0-9]*)?\}`)
+
+// rawPrecedence returns the precedence of the top-level operator of
+// the raw regular expression. Unbalanced and invalid expressions are
+// reported as concatenations, so they are never wrapped in a group:
+// they can be parts of a group split between several raw tokens.
+func rawPrecedence(value string) Precedence {
+	var (
+		items     int
+		repeated  bool
+		flagsOnly bool
+		alternate bool
+		depth     int
+	)
+
+	for i := 0; i < len(value); {
+		if depth == 0 {
+			switch value[i] {
+			case '|':
+				alternate = true
+			case '*', '+', '?':
+				repeated = true
+				i = skipLazy(value, i+1)
+
+				continue
+			case '{':
+				if bounds := repeatCountRe.FindString(value[i:]); bounds != "" {
+					repeated = true
+					i = skipLazy(value, i+len(bounds))
+
+					continue
+				}
+			}
+
+			items++
+			repeated = false
+			flagsOnly = false
+		}
+
+		switch value[i] {
+		case '\\':
+			n, literals := escapeLen(value[i:])
+			if depth == 0 && literals != 1 {
+				// `\Q...\E` can contain several characters.
+				items += literals - 1
+			}
+
+			i += n
+		case '[':
+			n := classLen(value[i:])
+			if n == 0 {
+				return PrecConcat
+			}
+
+			i += n
+		case '(':
+			if depth == 0 {
+				flagsOnly = isFlagsGroup(value[i:])
+			}
+
+			depth++
+			i++
+		case ')':
+			depth--
+			if depth < 0 {
+				return PrecConcat
+			}
+
+			i++
+		default:
+			_, size := utf8.DecodeRuneInString(value[i:])
+			i += size
+		}
+	}
+
+	switch {
+	case depth != 0:
+		return PrecConcat
+	case alternate:
+		return PrecAlternate
+	case items != 1 || flagsOnly:
+		return PrecConcat
+	case repeated:
+		return PrecRepeat
+	default:
+		return PrecAtom
+	}
+}
+
+// skipLazy skips the lazy modifier of the repetition.
+func skipLazy(value string, i int) int {
+	if i < len(value) && value[i] == '?' {
+		return i + 1
+	}
+
+	return i
+}
+
+// escapeLen returns the length of the escape sequence at the beginning
+// of the value and the count of characters that it matches.
+func escapeLen(value string) (n int, literals int) {
+	if len(value) < 2 {
+		return len(value), 1
+	}
+
+	switch value[1] {
+	case 'Q':
+		end := len(value)
+		if idx := strings.Index(value[2:], `\E`); idx >= 0 {
+			end = 2 + idx
+		}
+
+		literals = utf8.RuneCountInString(value[2:end])
+
+		if end < len(value) {
+			end += 2
+		}
+
+		return end, literals
+	case 'p', 'P', 'x':
+		if len(value) > 2 && value[2] == '{' {
+			if idx := strings.Index(value, "}"); idx >= 0 {
+				return idx + 1, 1
+			}
+		}
+
+		if value[1] == 'x' {
+			return min2(len(value), 4), 1
+		}
+
+		return min2(len(value), 3), 1
+	default:
+		_, size := utf8.DecodeRuneInString(value[1:])
+
+		return 1 + size, 1
+	}
+}
+
+// classLen returns the length of the bracket expression at the beginning
+// of the value. It returns 0 if the expression is not closed.
+func classLen(value string) int {
+	i := 1
+
+	if i < len(value) && value[i] == '^' {
+		i++
+	}
+
+	if i < len(value) && value[i] == ']' {
+		i++
+	}
+
+	for i < len(value) {
+		switch {
+		case value[i] == ']':
+			return i + 1
+		case value[i] == '\\':
+			n, _ := escapeLen(value[i:])
+			i += n
+		case value[i] == '[' && i+1 < len(value) && value[i+1] == ':':
+			if idx := strings.Index(value[i+2:], ":]"); idx >= 0 {
+				i += idx + 4
+			} else {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+
+	return 0
+}
+
+// isFlagsGroup returns true if the value starts with flags that are
+// not a group: `(?i)`.
+func isFlagsGroup(value string) bool {
+	if len(value) < 3 || value[1] != '?' {
+		return false
+	}
+
+	for i := 2; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == ')':
+			return true
+		case c == '-' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+func min2(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// GroupAlternations prepares tokens for a concatenation. Tokens with
+// a top-level alternation are wrapped by the function group, otherwise
+// the alternation would leak: `a|bc` instead of `(?:a|b)c`.
+func GroupAlternations(tokens []dialect.Token, group func(dialect.Token) dialect.Token) []dialect.Token {
+	if len(tokens) <= 1 {
+		return tokens
+	}
+
+	var grouped []dialect.Token
+
+	for i, tok := range tokens {
+		if PrecedenceOf(dialect.ASTOf(tok)) != PrecAlternate {
+			continue
+		}
+
+		if grouped == nil {
+			grouped = append(make([]dialect.Token, 0, len(tokens)), tokens...)
+		}
+
+		grouped[i] = group(tok)
+	}
+
+	if grouped == nil {
+		return tokens
+	}
+
+	return grouped
+}
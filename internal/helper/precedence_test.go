@@ -0,0 +1,142 @@
+package helper_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/helper"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+func TestPrecedenceOf(t *testing.T) {
+	t.Parallel()
+
+	raw := func(value string) *dialect.AST {
+		node := dialect.NewAST(dialect.KindRaw)
+		node.Value = value
+
+		return node
+	}
+
+	literal := func(value string) *dialect.AST {
+		node := dialect.NewAST(dialect.KindLiteral)
+		node.Value = value
+
+		return node
+	}
+
+	testCases := []struct {
+		Name     string
+		Node     *dialect.AST
+		Expected helper.Precedence
+	}{
+		{Name: "literal_rune", Node: literal("ы"), Expected: helper.PrecAtom},
+		{Name: "literal_text", Node: literal("ab"), Expected: helper.PrecConcat},
+		{Name: "class", Node: dialect.NewAST(dialect.KindClass), Expected: helper.PrecAtom},
+		{Name: "begin", Node: dialect.NewAST(dialect.KindBegin), Expected: helper.PrecAtom},
+		{Name: "empty", Node: dialect.NewAST(dialect.KindEmpty), Expected: helper.PrecConcat},
+		{Name: "flags", Node: dialect.NewAST(dialect.KindFlags), Expected: helper.PrecConcat},
+		{Name: "repeat", Node: dialect.NewAST(dialect.KindRepeat, literal("a")), Expected: helper.PrecRepeat},
+		{
+			Name:     "alternate",
+			Node:     dialect.NewAST(dialect.KindAlternate, literal("a"), literal("b")),
+			Expected: helper.PrecAlternate,
+		},
+		{
+			Name:     "alternate_single",
+			Node:     dialect.NewAST(dialect.KindAlternate, literal("a")),
+			Expected: helper.PrecAtom,
+		},
+		{
+			Name:     "concat",
+			Node:     dialect.NewAST(dialect.KindConcat, literal("a"), raw("b|c")),
+			Expected: helper.PrecConcat,
+		},
+		{
+			Name:     "concat_single",
+			Node:     dialect.NewAST(dialect.KindConcat, raw("b|c")),
+			Expected: helper.PrecAlternate,
+		},
+		{Name: "raw_char", Node: raw("a"), Expected: helper.PrecAtom},
+		{Name: "raw_escape", Node: raw(`\|`), Expected: helper.PrecAtom},
+		{Name: "raw_unicode", Node: raw(`\pL`), Expected: helper.PrecAtom},
+		{Name: "raw_hex", Node: raw(`\x41`), Expected: helper.PrecAtom},
+		{Name: "raw_class", Node: raw(`[|]`), Expected: helper.PrecAtom},
+		{Name: "raw_class_bracket", Node: raw(`[^]|]`), Expected: helper.PrecAtom},
+		{Name: "raw_class_posix", Node: raw(`[[:alpha:]|]`), Expected: helper.PrecAtom},
+		{Name: "raw_class_not_closed", Node: raw(`[a`), Expected: helper.PrecConcat},
+		{Name: "raw_group", Node: raw(`(a|b)`), Expected: helper.PrecAtom},
+		{Name: "raw_group_repeat", Node: raw(`(a|b)+?`), Expected: helper.PrecRepeat},
+		{Name: "raw_bounds", Node: raw(`a{2,}`), Expected: helper.PrecRepeat},
+		{Name: "raw_brace", Node: raw(`a{x}`), Expected: helper.PrecConcat},
+		{Name: "raw_quoted", Node: raw(`\Qa|b\E`), Expected: helper.PrecConcat},
+		{Name: "raw_alternate", Node: raw(`ab|c`), Expected: helper.PrecAlternate},
+		{Name: "raw_alternate_nested", Node: raw(`(a|b)c`), Expected: helper.PrecConcat},
+		{Name: "raw_flags", Node: raw(`(?i)`), Expected: helper.PrecConcat},
+		{Name: "raw_flags_group", Node: raw(`(?i:a|b)`), Expected: helper.PrecAtom},
+		{Name: "raw_not_closed", Node: raw(`(a`), Expected: helper.PrecConcat},
+		{Name: "raw_not_opened", Node: raw(`a)`), Expected: helper.PrecConcat},
+		{Name: "raw_alternate_not_closed", Node: raw(`a|(b`), Expected: helper.PrecConcat},
+		{Name: "raw_alternate_not_opened", Node: raw(`a)|b`), Expected: helper.PrecConcat},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := helper.PrecedenceOf(tc.Node); actual != tc.Expected {
+				t.Fatalf("Actual: %d, Expected: %d", actual, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestGroupAlternations(t *testing.T) {
+	t.Parallel()
+
+	group := func(token dialect.Token) dialect.Token {
+		return helper.TokenFunc(func(w dialect.StringByteWriter) (int, error) {
+			return helper.ProcessTokens(w, []dialect.Token{
+				helper.StringToken("(?:"), token, helper.ByteToken(')'),
+			})
+		})
+	}
+
+	testCases := []struct {
+		Name     string
+		Tokens   []dialect.Token
+		Expected string
+	}{{
+		Name:     "single",
+		Tokens:   []dialect.Token{helper.StringToken("a|b")},
+		Expected: "a|b",
+	}, {
+		Name:     "multiple",
+		Tokens:   []dialect.Token{helper.StringToken("a|b"), helper.StringToken("c"), helper.StringToken("d|e")},
+		Expected: "(?:a|b)c(?:d|e)",
+	}, {
+		Name:     "no_alternations",
+		Tokens:   []dialect.Token{helper.StringToken("(a|b)"), helper.StringToken("c")},
+		Expected: "(a|b)c",
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var sb strings.Builder
+
+			if _, err := helper.ProcessTokens(&sb, helper.GroupAlternations(tc.Tokens, group)); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual := sb.String(); actual != tc.Expected {
+				t.Fatalf("Actual: %q, Expected: %q", actual, tc.Expected)
+			}
+		})
+	}
+}
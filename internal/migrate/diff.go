@@ -0,0 +1,242 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around changes.
+const diffContext = 3
+
+// opKind is a kind of the line in the diff.
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type diffLine struct {
+	kind opKind
+	text string
+}
+
+// Diff returns the unified diff of two sources. It is empty if they
+// are equal.
+func Diff(filename string, original []byte, migrated []byte) string {
+	lines := diffLines(splitLines(string(original)), splitLines(string(migrated)))
+
+	var strBuilder strings.Builder
+
+	for start := 0; start < len(lines); {
+		hunkStart, hunkEnd, ok := nextHunk(lines, start)
+		if !ok {
+			break
+		}
+
+		if strBuilder.Len() == 0 {
+			fmt.Fprintf(&strBuilder, "--- a/%s\n+++ b/%s\n", filename, filename)
+		}
+
+		writeHunk(&strBuilder, lines, hunkStart, hunkEnd)
+
+		start = hunkEnd
+	}
+
+	return strBuilder.String()
+}
+
+// nextHunk returns bounds of the next group of changes with context.
+func nextHunk(lines []diffLine, start int) (hunkStart int, hunkEnd int, ok bool) {
+	first := start
+	for first < len(lines) && lines[first].kind == opEqual {
+		first++
+	}
+
+	if first == len(lines) {
+		return 0, 0, false
+	}
+
+	hunkStart = first - diffContext
+	if hunkStart < start {
+		hunkStart = start
+	}
+
+	// Changes are merged if they are separated by less than two contexts.
+	hunkEnd = first
+
+	for equal := 0; hunkEnd < len(lines) && equal <= 2*diffContext; hunkEnd++ {
+		if lines[hunkEnd].kind == opEqual {
+			equal++
+		} else {
+			equal = 0
+		}
+	}
+
+	// Trailing equal lines are trimmed to the context.
+	for hunkEnd > first && lines[hunkEnd-1].kind == opEqual {
+		hunkEnd--
+	}
+
+	hunkEnd += diffContext
+	if hunkEnd > len(lines) {
+		hunkEnd = len(lines)
+	}
+
+	return hunkStart, hunkEnd, true
+}
+
+func writeHunk(strBuilder *strings.Builder, lines []diffLine, hunkStart int, hunkEnd int) {
+	// Line numbers are 1-based.
+	originalLine, migratedLine := 1, 1
+
+	for _, line := range lines[:hunkStart] {
+		if line.kind != opInsert {
+			originalLine++
+		}
+
+		if line.kind != opDelete {
+			migratedLine++
+		}
+	}
+
+	var originalCount, migratedCount int
+
+	for _, line := range lines[hunkStart:hunkEnd] {
+		if line.kind != opInsert {
+			originalCount++
+		}
+
+		if line.kind != opDelete {
+			migratedCount++
+		}
+	}
+
+	fmt.Fprintf(strBuilder, "@@ -%s +%s @@\n",
+		hunkRange(originalLine, originalCount),
+		hunkRange(migratedLine, migratedCount),
+	)
+
+	for _, line := range lines[hunkStart:hunkEnd] {
+		fmt.Fprintf(strBuilder, "%c%s", line.kind, line.text)
+
+		if !strings.HasSuffix(line.text, "\n") {
+			strBuilder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprintf("%d", line)
+	default:
+		return fmt.Sprintf("%d,%d", line, count)
+	}
+}
+
+// splitLines splits the text to lines with their line endings, so the
+// last line without the line ending differs from the same line with it.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the shortest edit script by the Myers' algorithm.
+func diffLines(a []string, b []string) []diffLine {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+
+	// v contains the furthest x for each diagonal k = x - y.
+	v := make([]int, 2*maxD+3)
+	trace := make([][]int, 0, maxD+1)
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack restores the edit script from saved states of diagonals.
+func backtrack(a []string, b []string, trace [][]int, offset int, lastD int) []diffLine {
+	x, y := len(a), len(b)
+	lines := make([]diffLine, 0, x+y)
+
+	for d := lastD; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			lines = append(lines, diffLine{kind: opEqual, text: a[x]})
+		}
+
+		if x == prevX {
+			y--
+			lines = append(lines, diffLine{kind: opInsert, text: b[y]})
+		} else {
+			x--
+			lines = append(lines, diffLine{kind: opDelete, text: a[x]})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		lines = append(lines, diffLine{kind: opEqual, text: a[x]})
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return lines
+}
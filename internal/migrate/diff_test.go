@@ -0,0 +1,77 @@
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/migrate"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	lines := func(from int, to int, changed map[int]string) []byte {
+		var strBuilder strings.Builder
+
+		for i := from; i <= to; i++ {
+			if text, ok := changed[i]; ok {
+				strBuilder.WriteString(text)
+
+				continue
+			}
+
+			strBuilder.WriteString(string(rune('a'+i-1)) + "\n")
+		}
+
+		return []byte(strBuilder.String())
+	}
+
+	original := lines(1, 20, nil)
+	migrated := lines(1, 20, map[int]string{
+		2:  "B\n",
+		6:  "",
+		18: "r\nR\n",
+	})
+
+	expected := "--- a/dir/p.go\n" +
+		"+++ b/dir/p.go\n" +
+		"@@ -1,9 +1,8 @@\n" +
+		" a\n" +
+		"-b\n" +
+		"+B\n" +
+		" c\n" +
+		" d\n" +
+		" e\n" +
+		"-f\n" +
+		" g\n" +
+		" h\n" +
+		" i\n" +
+		"@@ -16,5 +15,6 @@\n" +
+		" p\n" +
+		" q\n" +
+		" r\n" +
+		"+R\n" +
+		" s\n" +
+		" t\n"
+
+	if actual := migrate.Diff("dir/p.go", original, migrated); actual != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+
+	noNewline := migrate.Diff("p.go", []byte("a\nb"), []byte("a\nb\n"))
+	expectedNoNewline := "--- a/p.go\n" +
+		"+++ b/p.go\n" +
+		"@@ -1,2 +1,2 @@\n" +
+		" a\n" +
+		"-b\n" +
+		"\\ No newline at end of file\n" +
+		"+b\n"
+
+	if noNewline != expectedNoNewline {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedNoNewline, noNewline)
+	}
+
+	if actual := migrate.Diff("dir/p.go", original, original); actual != "" {
+		t.Errorf("Expected empty diff, got:\n%s", actual)
+	}
+}
@@ -0,0 +1,497 @@
+// Package migrate rewrites regular expressions in Go source files to
+// rex builders.
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/generator"
+)
+
+// Import paths.
+const (
+	regexpPath = "regexp"
+	rexPath    = "github.com/hedhyw/rex/pkg/rex"
+)
+
+// generatedComment marks generated files, they are not changed.
+var generatedComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// Result of the migration of one file.
+type Result struct {
+	Filename string
+	Original []byte
+	// Migrated is the formatted source. It is equal to Original if
+	// nothing is replaced.
+	Migrated []byte
+	// Replaced is the number of replaced patterns.
+	Replaced int
+	// Reports describe patterns, that are not replaced.
+	Reports []Report
+}
+
+// Changed returns true if the source is changed.
+func (r Result) Changed() bool {
+	return r.Replaced > 0
+}
+
+// Report describes the pattern, that is not replaced.
+type Report struct {
+	Position token.Position
+	Pattern  string
+	Reason   string
+}
+
+// String implements fmt.Stringer.
+func (r Report) String() string {
+	return fmt.Sprintf("%s: %s: %s", r.Position, strconv.Quote(r.Pattern), r.Reason)
+}
+
+// edit replaces the source between start and end offsets.
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+// File replaces calls of regexp.Compile, regexp.MustCompile and
+// regexp.MatchString with constant patterns by rex builders:
+//
+//	regexp.MustCompile(`\d+`)         // rex.New(...).MustCompile()
+//	regexp.Compile(`\d+`)             // rex.New(...).Compile()
+//	regexp.MatchString(`\d+`, text)   // regexp.MatchString(rex.New(...).String(), text)
+//
+// Patterns, that can't be expressed without raw parts or that are not
+// equivalent after the conversion, are reported and kept as is. The
+// rex import is added and the regexp import is removed if it is not
+// used anymore. Generated files are not changed.
+func File(gen generator.Generator, filename string, src []byte) (Result, error) {
+	result := Result{
+		Filename: filename,
+		Original: src,
+		Migrated: src,
+		Replaced: 0,
+		Reports:  nil,
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return result, fmt.Errorf("parsing: %w", err)
+	}
+
+	regexpName, rexName := importNames(file)
+	if regexpName == "" || isGenerated(file) {
+		return result, nil
+	}
+
+	if rexName != "" && rexName != "rex" {
+		// The generated code refers to the package as rex.
+		return result, nil
+	}
+
+	m := migrator{
+		gen:      gen,
+		fset:     fset,
+		src:      src,
+		packages: resolvePackages(fset, file),
+		edits:    nil,
+		reports:  nil,
+		usages:   0,
+		replaced: 0,
+	}
+
+	ast.Inspect(file, m.visit)
+
+	result.Reports = m.reports
+	result.Replaced = m.replaced
+
+	if m.replaced == 0 {
+		return result, nil
+	}
+
+	m.edits = append(m.edits, m.importEdits(file, rexName == "")...)
+
+	migrated, err := format.Source(applyEdits(src, m.edits))
+	if err != nil {
+		return result, fmt.Errorf("formatting: %w", err)
+	}
+
+	result.Migrated = migrated
+
+	return result, nil
+}
+
+type migrator struct {
+	gen  generator.Generator
+	fset *token.FileSet
+	src  []byte
+	// packages are imported packages by identifiers, that refer to them.
+	packages map[*ast.Ident]*types.PkgName
+
+	edits   []edit
+	reports []Report
+	// usages is the number of references to the regexp package, that
+	// are kept.
+	usages   int
+	replaced int
+}
+
+func (m *migrator) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.SelectorExpr:
+		if m.isRegexp(n.X) {
+			m.usages++
+		}
+	case *ast.CallExpr:
+		if m.visitCall(n) {
+			// The call is already replaced.
+			return false
+		}
+	}
+
+	return true
+}
+
+// visitCall returns true if the call is replaced.
+func (m *migrator) visitCall(call *ast.CallExpr) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+
+	if !m.isRegexp(selector.X) {
+		return false
+	}
+
+	switch selector.Sel.Name {
+	case "Compile", "MustCompile", "MatchString":
+	default:
+		return false
+	}
+
+	pattern, ok := constantString(call.Args[0])
+	if !ok {
+		return false
+	}
+
+	code, ok := m.generate(call, pattern)
+	if !ok {
+		return false
+	}
+
+	switch selector.Sel.Name {
+	case "Compile":
+		m.replace(call, code+".Compile()")
+	case "MustCompile":
+		m.replace(call, code+".MustCompile()")
+	default:
+		// The signature of MatchString is kept, so only the pattern
+		// is replaced.
+		m.replace(call.Args[0], code+".String()")
+		m.usages++
+
+		for _, arg := range call.Args[1:] {
+			ast.Inspect(arg, m.visit)
+		}
+	}
+
+	m.replaced++
+
+	return true
+}
+
+// isRegexp returns true if the expression refers to the regexp package,
+// and not to a variable that shadows it.
+func (m *migrator) isRegexp(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	pkgName, ok := m.packages[ident]
+
+	return ok && pkgName.Imported().Path() == regexpPath
+}
+
+// resolvePackages resolves identifiers of the file by go/types. Imported
+// packages are replaced by empty ones, so errors are expected and
+// ignored: only scopes are needed.
+func resolvePackages(fset *token.FileSet, file *ast.File) map[*ast.Ident]*types.PkgName {
+	info := &types.Info{
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	conf := types.Config{
+		Importer: emptyImporter{},
+		Error:    func(error) {},
+	}
+
+	// The error is already reported to the Error function.
+	_, _ = conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	packages := make(map[*ast.Ident]*types.PkgName)
+
+	for ident, obj := range info.Uses {
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			packages[ident] = pkgName
+		}
+	}
+
+	return packages
+}
+
+// emptyImporter imports packages without declarations. It implements
+// types.Importer.
+type emptyImporter struct{}
+
+// Import implements types.Importer.
+func (emptyImporter) Import(path string) (*types.Package, error) {
+	pkg := types.NewPackage(path, path[strings.LastIndex(path, "/")+1:])
+	pkg.MarkComplete()
+
+	return pkg, nil
+}
+
+// generate returns the rex code or reports the pattern.
+func (m *migrator) generate(call *ast.CallExpr, pattern string) (string, bool) {
+	report := func(reason string) (string, bool) {
+		m.reports = append(m.reports, Report{
+			Position: m.fset.Position(call.Pos()),
+			Pattern:  pattern,
+			Reason:   reason,
+		})
+
+		return "", false
+	}
+
+	code, err := m.gen.Code(pattern)
+	if err != nil {
+		return report(err.Error())
+	}
+
+	if strings.Contains(code, "rex.Common.Raw(") {
+		return report("it contains constructions without rex equivalents")
+	}
+
+	if err := m.gen.Verify(pattern); err != nil {
+		return report(err.Error())
+	}
+
+	return code, true
+}
+
+func (m *migrator) replace(node ast.Node, text string) {
+	m.edits = append(m.edits, m.nodeEdit(node, text))
+}
+
+// importEdits adds the rex import and removes the regexp import, if it
+// is not used anymore.
+func (m *migrator) importEdits(file *ast.File, addRex bool) []edit {
+	var (
+		regexpSpec *ast.ImportSpec
+		regexpDecl *ast.GenDecl
+	)
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			importSpec, ok := spec.(*ast.ImportSpec)
+			if !ok {
+				continue
+			}
+
+			if path, _ := strconv.Unquote(importSpec.Path.Value); path == regexpPath {
+				regexpSpec, regexpDecl = importSpec, genDecl
+			}
+		}
+	}
+
+	rexSpec := strconv.Quote(rexPath)
+
+	if m.usages == 0 && len(regexpDecl.Specs) == 1 {
+		if addRex {
+			// The only import is replaced, so groups are kept.
+			return []edit{m.nodeEdit(regexpSpec, rexSpec)}
+		}
+
+		return []edit{m.nodeEdit(regexpDecl, "")}
+	}
+
+	var edits []edit
+
+	if m.usages == 0 {
+		edits = append(edits, m.removeLine(regexpSpec))
+	}
+
+	if !addRex {
+		return edits
+	}
+
+	if regexpDecl.Lparen.IsValid() {
+		lastSpec, _ := regexpDecl.Specs[len(regexpDecl.Specs)-1].(*ast.ImportSpec)
+		if lastSpec != regexpSpec && !isStandard(lastSpec) {
+			// The import is added to the last group of non-standard
+			// packages, gofmt sorts it.
+			offset := m.fset.Position(lastSpec.End()).Offset
+
+			return append(edits, edit{start: offset, end: offset, text: "\n" + rexSpec})
+		}
+
+		// The import is added as a separate group.
+		offset := m.fset.Position(regexpDecl.Rparen).Offset
+
+		return append(edits, edit{start: offset, end: offset, text: "\n" + rexSpec + "\n"})
+	}
+
+	spec := m.src[m.fset.Position(regexpSpec.Pos()).Offset:m.fset.Position(regexpDecl.End()).Offset]
+
+	return append(edits, m.nodeEdit(regexpDecl, "import (\n"+string(spec)+"\n\n"+rexSpec+"\n)"))
+}
+
+func (m *migrator) nodeEdit(node ast.Node, text string) edit {
+	return edit{
+		start: m.fset.Position(node.Pos()).Offset,
+		end:   m.fset.Position(node.End()).Offset,
+		text:  text,
+	}
+}
+
+// removeLine removes the spec with its line, so the import group is
+// not split by an empty line.
+func (m *migrator) removeLine(spec *ast.ImportSpec) edit {
+	e := m.nodeEdit(spec, "")
+
+	start := bytes.LastIndexByte(m.src[:e.start], '\n') + 1
+	end := bytes.IndexByte(m.src[e.end:], '\n')
+
+	if end == -1 || len(bytes.TrimSpace(m.src[start:e.start])) != 0 ||
+		len(bytes.TrimSpace(m.src[e.end:e.end+end])) != 0 {
+		// There is something else on the line.
+		return e
+	}
+
+	e.start, e.end = start, e.end+end+1
+
+	return e
+}
+
+// applyEdits returns the source with replaced parts. Edits don't
+// overlap.
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+
+	last := 0
+
+	for _, e := range edits {
+		_, _ = buf.Write(src[last:e.start])
+		_, _ = buf.WriteString(e.text)
+		last = e.end
+	}
+
+	_, _ = buf.Write(src[last:])
+
+	return buf.Bytes()
+}
+
+// importNames returns names of regexp and rex packages in the file.
+// They are empty if packages are not imported.
+func importNames(file *ast.File) (regexpName string, rexName string) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		switch path {
+		case regexpPath:
+			regexpName = name
+		case rexPath:
+			rexName = name
+		}
+	}
+
+	if regexpName == "_" || regexpName == "." {
+		regexpName = ""
+	}
+
+	return regexpName, rexName
+}
+
+// isStandard returns true if the import is from the standard library,
+// their paths don't contain dots in the first element.
+func isStandard(spec *ast.ImportSpec) bool {
+	path, _ := strconv.Unquote(spec.Path.Value)
+
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+
+		for _, comment := range group.List {
+			if generatedComment.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// constantString returns the value of a string literal or of a
+// concatenation of string literals.
+func constantString(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+
+		value, err := strconv.Unquote(expr.Value)
+
+		return value, err == nil
+	case *ast.ParenExpr:
+		return constantString(expr.X)
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+
+		left, ok := constantString(expr.X)
+		if !ok {
+			return "", false
+		}
+
+		right, ok := constantString(expr.Y)
+
+		return left + right, ok
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,289 @@
+package migrate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/generator"
+	"github.com/hedhyw/rex/internal/migrate"
+)
+
+// nolint: funlen // Test cases.
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		src      string
+		expected string
+		replaced int
+	}{{
+		name: "must_compile_replaces_import",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"var re = regexp.MustCompile(`\\d+`)\n",
+		expected: "package p\n\n" +
+			"import \"github.com/hedhyw/rex/pkg/rex\"\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Digits().Repeat().OneOrMore(),\n" +
+			").MustCompile()\n",
+		replaced: 1,
+	}, {
+		name: "compile_adds_import",
+		src: "package p\n\n" +
+			"import (\n\t\"fmt\"\n\t\"regexp\"\n)\n\n" +
+			"func f(s string) (*regexp.Regexp, error) {\n" +
+			"\tfmt.Println(s)\n\n" +
+			"\treturn regexp.Compile(\"a\" + (`b`))\n" +
+			"}\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"fmt\"\n\t\"regexp\"\n\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"func f(s string) (*regexp.Regexp, error) {\n" +
+			"\tfmt.Println(s)\n\n" +
+			"\treturn rex.New(\n" +
+			"\t\trex.Common.Text(\"ab\"),\n" +
+			"\t).Compile()\n" +
+			"}\n",
+		replaced: 1,
+	}, {
+		name: "removes_import_from_group",
+		src: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\t\"strings\"\n)\n\n" +
+			"var re = regexp.MustCompile(strings.Repeat(`a`, 2) + `b`)\n\n" +
+			"var re2 = regexp.MustCompile(`b`)\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\t\"strings\"\n\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var re = regexp.MustCompile(strings.Repeat(`a`, 2) + `b`)\n\n" +
+			"var re2 = rex.New(\n" +
+			"\trex.Chars.Single('b'),\n" +
+			").MustCompile()\n",
+		replaced: 1,
+	}, {
+		name: "removes_unused_import_from_group",
+		src: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\t\"strings\"\n)\n\n" +
+			"var re = regexp.MustCompile(`b`)\n\n" +
+			"var s = strings.Repeat(`a`, 2)\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"strings\"\n\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Single('b'),\n" +
+			").MustCompile()\n\n" +
+			"var s = strings.Repeat(`a`, 2)\n",
+		replaced: 1,
+	}, {
+		name: "adds_import_to_last_group",
+		src: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\n\t\"github.com/hedhyw/rex/pkg/dialect\"\n)\n\n" +
+			"var re = regexp.MustCompile(`b`)\n\n" +
+			"var _ dialect.Kind\n",
+		expected: "package p\n\n" +
+			"import (\n" +
+			"\t\"github.com/hedhyw/rex/pkg/dialect\"\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Single('b'),\n" +
+			").MustCompile()\n\n" +
+			"var _ dialect.Kind\n",
+		replaced: 1,
+	}, {
+		name: "case_insensitive",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"var re = regexp.MustCompile(`(?i)[a-z]+\\pL`)\n",
+		expected: "package p\n\n" +
+			"import \"github.com/hedhyw/rex/pkg/rex\"\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Flags.CaseInsensitive().Group(rex.Chars.Range('a', 'z')).Repeat().OneOrMore(),\n" +
+			"\trex.Flags.CaseInsensitive().Group(rex.Chars.UnicodeByName(\"L\")),\n" +
+			").MustCompile()\n",
+		replaced: 1,
+	}, {
+		name: "match_string_keeps_call",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"func f(s string) bool {\n" +
+			"\tok, _ := regexp.MatchString(`^a`, s)\n\n" +
+			"\treturn ok\n" +
+			"}\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"func f(s string) bool {\n" +
+			"\tok, _ := regexp.MatchString(rex.New(\n" +
+			"\t\trex.Chars.Begin(),\n" +
+			"\t\trex.Chars.Single('a'),\n" +
+			"\t).String(), s)\n\n" +
+			"\treturn ok\n" +
+			"}\n",
+		replaced: 1,
+	}, {
+		name: "rex_imported_removes_regexp",
+		src: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\n\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var (\n" +
+			"\ta = regexp.MustCompile(`a`)\n" +
+			"\tb = rex.New(rex.Chars.Digits()).MustCompile()\n" +
+			")\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var (\n" +
+			"\ta = rex.New(\n" +
+			"\t\trex.Chars.Single('a'),\n" +
+			"\t).MustCompile()\n" +
+			"\tb = rex.New(rex.Chars.Digits()).MustCompile()\n" +
+			")\n",
+		replaced: 1,
+	}, {
+		name: "regexp_alias",
+		src: "package p\n\n" +
+			"import re \"regexp\"\n\n" +
+			"var r = re.MustCompile(`a`)\n",
+		expected: "package p\n\n" +
+			"import \"github.com/hedhyw/rex/pkg/rex\"\n\n" +
+			"var r = rex.New(\n" +
+			"\trex.Chars.Single('a'),\n" +
+			").MustCompile()\n",
+		replaced: 1,
+	}, {
+		name: "not_constant",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"const pattern = `a`\n\n" +
+			"func f(s string) *regexp.Regexp {\n" +
+			"\tregexp.MustCompile(pattern)\n\n" +
+			"\treturn regexp.MustCompile(s + `a`)\n" +
+			"}\n",
+		replaced: 0,
+	}, {
+		name: "shadowed_package",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"type compiler struct{}\n\n" +
+			"func (compiler) MustCompile(string) *regexp.Regexp { return nil }\n\n" +
+			"func f() {\n" +
+			"\tregexp := compiler{}\n" +
+			"\tregexp.MustCompile(`a`)\n" +
+			"}\n",
+		replaced: 0,
+	}, {
+		name: "shadowed_in_scope",
+		src: "package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"type compiler struct{}\n\n" +
+			"func (compiler) MustCompile(string) *regexp.Regexp { return nil }\n\n" +
+			"func f(regexp compiler) {\n" +
+			"\tregexp.MustCompile(`a`)\n" +
+			"}\n\n" +
+			"var re = regexp.MustCompile(`b`)\n",
+		expected: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\n" +
+			"\t\"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"type compiler struct{}\n\n" +
+			"func (compiler) MustCompile(string) *regexp.Regexp { return nil }\n\n" +
+			"func f(regexp compiler) {\n" +
+			"\tregexp.MustCompile(`a`)\n" +
+			"}\n\n" +
+			"var re = rex.New(\n" +
+			"\trex.Chars.Single('b'),\n" +
+			").MustCompile()\n",
+		replaced: 1,
+	}, {
+		name: "generated",
+		src: "// Code generated by hand. DO NOT EDIT.\n\n" +
+			"package p\n\n" +
+			"import \"regexp\"\n\n" +
+			"var re = regexp.MustCompile(`a`)\n",
+		replaced: 0,
+	}, {
+		name: "rex_alias",
+		src: "package p\n\n" +
+			"import (\n\t\"regexp\"\n\n\tr \"github.com/hedhyw/rex/pkg/rex\"\n)\n\n" +
+			"var re = regexp.MustCompile(`a`)\n\n" +
+			"var _ = r.New\n",
+		replaced: 0,
+	}}
+
+	for _, testCaseNotInParallel := range testCases {
+		testCase := testCaseNotInParallel
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := migrate.File(generator.New(), "p.go", []byte(testCase.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Replaced != testCase.replaced {
+				t.Errorf("Replaced: expected %d, got %d", testCase.replaced, result.Replaced)
+			}
+
+			expected := testCase.expected
+			if expected == "" {
+				expected = testCase.src
+			}
+
+			if string(result.Migrated) != expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", expected, result.Migrated)
+			}
+
+			if len(result.Reports) != 0 {
+				t.Errorf("Unexpected reports: %v", result.Reports)
+			}
+		})
+	}
+}
+
+func TestFileReports(t *testing.T) {
+	t.Parallel()
+
+	src := "package p\n\n" +
+		"import \"regexp\"\n\n" +
+		"var (\n" +
+		"\ta = regexp.MustCompile(`a**`)\n" +
+		"\tb = regexp.MustCompile(`b`)\n" +
+		")\n"
+
+	result, err := migrate.File(generator.New(), "p.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Reports) != 1 {
+		t.Fatalf("Expected one report, got: %v", result.Reports)
+	}
+
+	const expectedReport = "p.go:6:6: \"a**\": failed to parse regexp"
+	if report := result.Reports[0].String(); !strings.HasPrefix(report, expectedReport) {
+		t.Errorf("Expected report %q, got %q", expectedReport, report)
+	}
+
+	// The regexp package is still used by the reported pattern.
+	if !strings.Contains(string(result.Migrated), "\t\"regexp\"\n") ||
+		!strings.Contains(string(result.Migrated), "regexp.MustCompile(`a**`)") {
+		t.Errorf("Reported pattern is changed:\n%s", result.Migrated)
+	}
+
+	if result.Replaced != 1 || !result.Changed() {
+		t.Errorf("Expected one replaced pattern, got %d", result.Replaced)
+	}
+}
+
+func TestFileInvalid(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("package p\n\nfunc {")
+
+	result, err := migrate.File(generator.New(), "p.go", src)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if result.Changed() || string(result.Migrated) != string(src) {
+		t.Errorf("Invalid source is changed: %s", result.Migrated)
+	}
+}
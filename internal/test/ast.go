@@ -0,0 +1,117 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// FormatAST returns a short text representation of the tree,
+// that is easy to compare in tests.
+//
+// Example: `Concat(Literal("a"), Repeat{1,-1}(Class[0-9]))`.
+func FormatAST(node *dialect.AST) string {
+	var sb strings.Builder
+
+	formatAST(&sb, node)
+
+	return sb.String()
+}
+
+func formatAST(sb *strings.Builder, node *dialect.AST) {
+	if node == nil {
+		sb.WriteString("nil")
+
+		return
+	}
+
+	sb.WriteString(node.Kind.String())
+
+	//nolint: exhaustive // Other kinds don't have attributes.
+	switch node.Kind {
+	case dialect.KindRaw, dialect.KindLiteral:
+		fmt.Fprintf(sb, "(%q)", node.Value)
+	case dialect.KindClass:
+		formatClass(sb, node)
+	case dialect.KindGroup:
+		switch {
+		case node.Name != "":
+			fmt.Fprintf(sb, "<%s>", node.Name)
+		case !node.Capture:
+			fmt.Fprintf(sb, "?%s:", node.Flags)
+		}
+	case dialect.KindRepeat:
+		fmt.Fprintf(sb, "{%d,%d}", node.Min, node.Max)
+
+		if node.PreferFewer {
+			sb.WriteByte('?')
+		}
+	case dialect.KindFlags:
+		fmt.Fprintf(sb, "(%s)", node.Flags)
+	case dialect.KindLookAhead, dialect.KindLookBehind:
+		if node.Negated {
+			sb.WriteByte('!')
+		}
+	case dialect.KindBackRef:
+		if node.Name != "" {
+			fmt.Fprintf(sb, "<%s>", node.Name)
+		} else {
+			fmt.Fprintf(sb, "<%d>", node.Index)
+		}
+	}
+
+	if len(node.Sub) == 0 {
+		return
+	}
+
+	sb.WriteByte('(')
+
+	for i, sub := range node.Sub {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		formatAST(sb, sub)
+	}
+
+	sb.WriteByte(')')
+}
+
+func formatClass(sb *strings.Builder, node *dialect.AST) {
+	if node.Value != "" {
+		fmt.Fprintf(sb, "(%s)", node.Value)
+	}
+
+	sb.WriteByte('[')
+
+	if node.Negated {
+		sb.WriteByte('^')
+	}
+
+	for i := 0; i+1 < len(node.Ranges); i += 2 {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		lo, hi := node.Ranges[i], node.Ranges[i+1]
+
+		if lo == hi {
+			sb.WriteString(formatRune(lo))
+
+			continue
+		}
+
+		sb.WriteString(formatRune(lo) + "-" + formatRune(hi))
+	}
+
+	sb.WriteByte(']')
+}
+
+func formatRune(r rune) string {
+	if r > ' ' && r < 0x7F {
+		return string(r)
+	}
+
+	return fmt.Sprintf("U+%04X", r)
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -32,6 +33,38 @@ func (testCases RexTestCasesSlice) Run(t *testing.T) {
 			if b.String() != tc.Expected {
 				t.Fatalf("Actual: %#q, Expected: %#q", b.String(), tc.Expected)
 			}
+
+			if err := b.Err(); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+// RexErrTestCase is a test case for tokens that report an error.
+type RexErrTestCase struct {
+	Name  string
+	Chain []dialect.Token
+	Err   error
+}
+
+// RexErrTestCasesSlice helps to process slice of error test cases.
+type RexErrTestCasesSlice []RexErrTestCase
+
+// Run runs in parallel.
+func (testCases RexErrTestCasesSlice) Run(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := rex.NewE(tc.Chain...)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("Actual: %v, Expected: %v", err, tc.Err)
+			}
 		})
 	}
 }
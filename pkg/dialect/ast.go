@@ -0,0 +1,205 @@
+package dialect
+
+import "strings"
+
+// Kind specifies a type of the AST node.
+type Kind uint8
+
+// Kinds of AST nodes.
+const (
+	// KindEmpty matches an empty string.
+	KindEmpty Kind = iota
+	// KindRaw is a regular expression that is not parsed. It is stored in
+	// AST.Value as is.
+	KindRaw
+	// KindLiteral matches the text from AST.Value.
+	KindLiteral
+	// KindClass matches one character from AST.Ranges. If AST.Negated
+	// is set, then it matches any character that is not in AST.Ranges.
+	KindClass
+	// KindAnyChar matches any character: `.`.
+	KindAnyChar
+	// KindBegin matches the begin of a text or a line: `^`.
+	KindBegin
+	// KindEnd matches the end of a text or a line: `$`.
+	KindEnd
+	// KindBeginOfText matches the begin of a text: `\A`.
+	KindBeginOfText
+	// KindEndOfText matches the end of a text: `\z`.
+	KindEndOfText
+	// KindWordBoundary matches an ASCII word boundary: `\b`.
+	KindWordBoundary
+	// KindNoWordBoundary matches not an ASCII word boundary: `\B`.
+	KindNoWordBoundary
+	// KindConcat matches all AST.Sub one after another.
+	KindConcat
+	// KindAlternate matches one of AST.Sub.
+	KindAlternate
+	// KindGroup groups AST.Sub[0]. The group is captured if AST.Capture
+	// is set, AST.Name is a name of a captured group. AST.Flags are flags,
+	// that are applied to the group.
+	KindGroup
+	// KindRepeat repeats AST.Sub[0] from AST.Min to AST.Max times.
+	// AST.Max is -1 if there is no upper bound.
+	KindRepeat
+	// KindFlags sets AST.Flags till the end of the current group.
+	KindFlags
+	// KindLookAhead matches if AST.Sub[0] matches next, without consuming
+	// characters. It matches if AST.Sub[0] doesn't match, if AST.Negated
+	// is set. It is not supported by RE2.
+	KindLookAhead
+	// KindLookBehind matches if AST.Sub[0] matches before, without consuming
+	// characters. It matches if AST.Sub[0] doesn't match, if AST.Negated
+	// is set. It is not supported by RE2.
+	KindLookBehind
+	// KindAtomic matches AST.Sub[0] without backtracking into it.
+	// It is not supported by RE2.
+	KindAtomic
+	// KindBackRef matches the same text as the captured group with AST.Name
+	// or with AST.Index if the name is empty. It is not supported by RE2.
+	KindBackRef
+)
+
+var kindNames = []string{
+	KindEmpty:          "Empty",
+	KindRaw:            "Raw",
+	KindLiteral:        "Literal",
+	KindClass:          "Class",
+	KindAnyChar:        "AnyChar",
+	KindBegin:          "Begin",
+	KindEnd:            "End",
+	KindBeginOfText:    "BeginOfText",
+	KindEndOfText:      "EndOfText",
+	KindWordBoundary:   "WordBoundary",
+	KindNoWordBoundary: "NoWordBoundary",
+	KindConcat:         "Concat",
+	KindAlternate:      "Alternate",
+	KindGroup:          "Group",
+	KindRepeat:         "Repeat",
+	KindFlags:          "Flags",
+	KindLookAhead:      "LookAhead",
+	KindLookBehind:     "LookBehind",
+	KindAtomic:         "Atomic",
+	KindBackRef:        "BackRef",
+}
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+
+	return "Unknown"
+}
+
+// AST is a structured representation of a token.
+//
+// Fields that are not related to the Kind are empty.
+type AST struct {
+	Kind Kind
+	// Sub contains children nodes.
+	Sub []*AST
+
+	// Value is a text for KindLiteral, a regular expression for KindRaw
+	// and a name of a predefined class for KindClass, as it is written
+	// inside brackets. Example: `\d`, `[:alpha:]`, `\p{Greek}`.
+	Value string
+
+	// Ranges are pairs of runes [lo, hi] of KindClass. They are sorted
+	// and don't overlap.
+	Ranges []rune
+	// Negated inverts KindClass, KindLookAhead and KindLookBehind.
+	Negated bool
+
+	// Capture is set for captured KindGroup.
+	Capture bool
+	// Name of the captured KindGroup or of the group, that KindBackRef
+	// refers to.
+	Name string
+	// Index of the group, that KindBackRef refers to.
+	Index int
+	// Flags of KindGroup or KindFlags. Example: `i-m`.
+	Flags string
+
+	// Min is a lower bound of KindRepeat.
+	Min int
+	// Max is an upper bound of KindRepeat, -1 means no limit.
+	Max int
+	// PreferFewer is set for non-greedy KindRepeat.
+	PreferFewer bool
+}
+
+// NewAST creates a node of the kind with children.
+func NewAST(kind Kind, sub ...*AST) *AST {
+	return &AST{
+		Kind:        kind,
+		Sub:         sub,
+		Value:       "",
+		Ranges:      nil,
+		Negated:     false,
+		Capture:     false,
+		Name:        "",
+		Index:       0,
+		Flags:       "",
+		Min:         0,
+		Max:         0,
+		PreferFewer: false,
+	}
+}
+
+// Node is an optional interface for tokens, that can expose their
+// structure.
+type Node interface {
+	Token
+
+	// AST returns a structured tree of the token. The tree is created
+	// on each call, so it is safe to modify it.
+	AST() *AST
+}
+
+// ASTOf returns the structure of the token. If the token doesn't
+// implement Node, then it returns KindRaw with the written token.
+func ASTOf(token Token) *AST {
+	if node, ok := token.(Node); ok {
+		return node.AST()
+	}
+
+	var sb strings.Builder
+
+	_, _ = token.WriteTo(&sb)
+
+	node := NewAST(KindRaw)
+	node.Value = sb.String()
+
+	return node
+}
+
+// ConcatAST returns the structure of tokens, that follow one after another.
+func ConcatAST(tokens ...Token) *AST {
+	switch len(tokens) {
+	case 0:
+		return NewAST(KindEmpty)
+	case 1:
+		return ASTOf(tokens[0])
+	}
+
+	sub := make([]*AST, 0, len(tokens))
+
+	for _, t := range tokens {
+		sub = append(sub, ASTOf(t))
+	}
+
+	return NewAST(KindConcat, sub...)
+}
+
+// Walk traverses the tree in depth-first order. If fn returns false,
+// then children of the node are skipped.
+func (a *AST) Walk(fn func(node *AST) bool) {
+	if a == nil || !fn(a) {
+		return
+	}
+
+	for _, sub := range a.Sub {
+		sub.Walk(fn)
+	}
+}
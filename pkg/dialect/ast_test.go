@@ -0,0 +1,98 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/hedhyw/rex/internal/helper"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+func TestASTOf(t *testing.T) {
+	t.Parallel()
+
+	node := dialect.ASTOf(helper.StringToken("a+"))
+
+	switch {
+	case node.Kind != dialect.KindRaw:
+		t.Fatalf("Actual: %s, Expected: %s", node.Kind, dialect.KindRaw)
+	case node.Value != "a+":
+		t.Fatalf("Actual: %q, Expected: %q", node.Value, "a+")
+	}
+}
+
+func TestConcatAST(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		if node := dialect.ConcatAST(); node.Kind != dialect.KindEmpty {
+			t.Fatalf("Actual: %s, Expected: %s", node.Kind, dialect.KindEmpty)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		t.Parallel()
+
+		if node := dialect.ConcatAST(helper.ByteToken('a')); node.Kind != dialect.KindRaw {
+			t.Fatalf("Actual: %s, Expected: %s", node.Kind, dialect.KindRaw)
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		t.Parallel()
+
+		node := dialect.ConcatAST(helper.ByteToken('a'), helper.ByteToken('b'))
+
+		switch {
+		case node.Kind != dialect.KindConcat:
+			t.Fatalf("Actual: %s, Expected: %s", node.Kind, dialect.KindConcat)
+		case len(node.Sub) != 2:
+			t.Fatalf("Actual: %d, Expected: %d", len(node.Sub), 2)
+		case node.Sub[1].Value != "b":
+			t.Fatalf("Actual: %q, Expected: %q", node.Sub[1].Value, "b")
+		}
+	})
+}
+
+func TestASTWalk(t *testing.T) {
+	t.Parallel()
+
+	node := dialect.NewAST(
+		dialect.KindConcat,
+		dialect.NewAST(dialect.KindGroup, dialect.NewAST(dialect.KindLiteral)),
+		dialect.NewAST(dialect.KindAnyChar),
+	)
+
+	var visited []dialect.Kind
+
+	node.Walk(func(node *dialect.AST) bool {
+		visited = append(visited, node.Kind)
+
+		return node.Kind != dialect.KindGroup
+	})
+
+	expected := []dialect.Kind{dialect.KindConcat, dialect.KindGroup, dialect.KindAnyChar}
+
+	if len(visited) != len(expected) {
+		t.Fatalf("Actual: %v, Expected: %v", visited, expected)
+	}
+
+	for i := range expected {
+		if visited[i] != expected[i] {
+			t.Fatalf("Actual: %v, Expected: %v", visited, expected)
+		}
+	}
+}
+
+func TestKindString(t *testing.T) {
+	t.Parallel()
+
+	if actual := dialect.KindRepeat.String(); actual != "Repeat" {
+		t.Fatalf("Actual: %q, Expected: %q", actual, "Repeat")
+	}
+
+	if actual := dialect.Kind(255).String(); actual != "Unknown" {
+		t.Fatalf("Actual: %q, Expected: %q", actual, "Unknown")
+	}
+}
@@ -0,0 +1,185 @@
+package base_test
+
+import (
+	"testing"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+// nolint: funlen // Unit test.
+func TestAST(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Token    dialect.Token
+		Expected string
+	}{{
+		Name:     "Text",
+		Token:    base.Common.Text("a.b"),
+		Expected: `Literal("a.b")`,
+	}, {
+		Name:     "TextEmpty",
+		Token:    base.Common.Text(""),
+		Expected: `Empty`,
+	}, {
+		Name:     "Raw",
+		Token:    base.Common.Raw("a|b"),
+		Expected: `Raw("a|b")`,
+	}, {
+		Name:     "RawVerbose",
+		Token:    base.Common.RawVerbose("a # comment"),
+		Expected: `Raw("a")`,
+	}, {
+		Name:     "Single",
+		Token:    base.Chars.Single('.'),
+		Expected: `Literal(".")`,
+	}, {
+		Name:     "Digits",
+		Token:    base.Chars.Digits(),
+		Expected: `Class(\d)[0-9]`,
+	}, {
+		Name:     "Alphabetic",
+		Token:    base.Chars.Alphabetic(),
+		Expected: `Class([:alpha:])[A-Z a-z]`,
+	}, {
+		Name:     "Unicode",
+		Token:    base.Chars.UnicodeByName("Nko"),
+		Expected: `Class(\p{Nko})[U+07C0-U+07FA U+07FD-U+07FF]`,
+	}, {
+		Name:     "Range",
+		Token:    base.Chars.Range('a', 'f'),
+		Expected: `Class[a-f]`,
+	}, {
+		Name:     "Runes",
+		Token:    base.Chars.Runes("cab"),
+		Expected: `Class[a-c]`,
+	}, {
+		Name:     "Any",
+		Token:    base.Chars.Any(),
+		Expected: `AnyChar`,
+	}, {
+		Name:     "Begin",
+		Token:    base.Chars.Begin(),
+		Expected: `Begin`,
+	}, {
+		Name:     "EndOfText",
+		Token:    base.Chars.EndOfText(),
+		Expected: `EndOfText`,
+	}, {
+		Name: "Class",
+		Token: base.Common.Class(
+			base.Chars.Range('a', 'c'),
+			base.Chars.Digits(),
+			base.Chars.Single('b'),
+			base.Chars.Any(),
+		),
+		Expected: `Class[. 0-9 a-c]`,
+	}, {
+		Name:     "NotClass",
+		Token:    base.Common.NotClass(base.Chars.Digits()),
+		Expected: `Class[^0-9]`,
+	}, {
+		Name:     "ClassWithRaw",
+		Token:    base.Common.Class(base.Common.Raw("a-z"), base.Chars.Digits()),
+		Expected: `Raw("[a-z\\d]")`,
+	}, {
+		Name:     "ClassEmpty",
+		Token:    base.Common.Class(),
+		Expected: `Empty`,
+	}, {
+		Name:     "Group",
+		Token:    base.Group.Define(base.Common.Text("a"), base.Chars.Digits()),
+		Expected: `Group(Concat(Literal("a"), Class(\d)[0-9]))`,
+	}, {
+		Name:     "GroupNamed",
+		Token:    base.Group.Define(base.Common.Text("a")).WithName("name"),
+		Expected: `Group<name>(Literal("a"))`,
+	}, {
+		Name:     "GroupNonCaptured",
+		Token:    base.Group.NonCaptured(base.Common.Text("a")),
+		Expected: `Group?:(Literal("a"))`,
+	}, {
+		Name:     "GroupFlags",
+		Token:    base.Flags.CaseInsensitive().Group(base.Common.Text("a")),
+		Expected: `Group?i:(Literal("a"))`,
+	}, {
+		Name:     "GroupEmpty",
+		Token:    base.Group.Define(),
+		Expected: `Empty`,
+	}, {
+		Name:     "LookAhead",
+		Token:    base.Group.LookAhead(base.Common.Text("a")),
+		Expected: `LookAhead(Literal("a"))`,
+	}, {
+		Name:     "NegativeLookBehind",
+		Token:    base.Group.NegativeLookBehind(base.Common.Text("a")),
+		Expected: `LookBehind!(Literal("a"))`,
+	}, {
+		Name:     "Atomic",
+		Token:    base.Group.Atomic(base.Common.Text("a")),
+		Expected: `Atomic(Literal("a"))`,
+	}, {
+		Name:     "BackRef",
+		Token:    base.Common.BackRef("name"),
+		Expected: `BackRef<name>`,
+	}, {
+		Name:     "BackRefIndex",
+		Token:    base.Common.BackRef("2"),
+		Expected: `BackRef<2>`,
+	}, {
+		Name:     "Composite",
+		Token:    base.Group.Composite(base.Common.Text("a"), base.Common.Text("b")),
+		Expected: `Group(Alternate(Literal("a"), Literal("b")))`,
+	}, {
+		Name:     "Flags",
+		Token:    base.Flags.Combine(base.Flags.CaseInsensitive(), base.Flags.Multiline().Disable()),
+		Expected: `Flags(i-m)`,
+	}, {
+		Name:     "RepeatOneOrMore",
+		Token:    base.Chars.Digits().Repeat().OneOrMore(),
+		Expected: `Repeat{1,-1}(Class(\d)[0-9])`,
+	}, {
+		Name:     "RepeatBetweenPreferFewer",
+		Token:    base.Group.Define(base.Common.Text("a")).Repeat().BetweenPreferFewer(2, 3),
+		Expected: `Repeat{2,3}?(Group(Literal("a")))`,
+	}, {
+		Name:     "RepeatEmpty",
+		Token:    base.Common.Class().Repeat().OneOrMore(),
+		Expected: `Empty`,
+	}, {
+		Name:     "NumberRange",
+		Token:    base.Helper.NumberRange(5, 12),
+		Expected: `Group?:(Alternate(Group?:(Class[5-9]), Group?:(Concat(Literal("1"), Class[0-2]))))`,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := test.FormatAST(dialect.ASTOf(tc.Token))
+			if actual != tc.Expected {
+				t.Fatalf("Actual: %s, Expected: %s", actual, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestASTIsolated(t *testing.T) {
+	t.Parallel()
+
+	token := base.Chars.Range('a', 'z')
+
+	node := dialect.ASTOf(token)
+	node.Ranges[0] = 'b'
+
+	const expected = `Class[a-z]`
+
+	if actual := test.FormatAST(dialect.ASTOf(token)); actual != expected {
+		t.Fatalf("Actual: %s, Expected: %s", actual, expected)
+	}
+}
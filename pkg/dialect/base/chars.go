@@ -1,12 +1,16 @@
 package base
 
 import (
+	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 
-	"github.com/hedhyw/rex/internal/helper"
+	"github.com/hedhyw/rex/internal/charclass"
 	"github.com/hedhyw/rex/pkg/dialect"
 )
 
@@ -19,11 +23,15 @@ type CharsBaseDialect dialect.Dialect
 // Chars contains character class elements.
 const Chars CharsBaseDialect = "CharsBaseDialect"
 
+// unicodeAnyName is a special unicode class supported by RE2,
+// that matches any character.
+const unicodeAnyName = "Any"
+
 // Digits is an alias to [0-9]. ASCII.
 //
 // Regex: `\d`.
 func (CharsBaseDialect) Digits() ClassToken {
-	return newClassToken(helper.StringToken(`\d`)).withoutBrackets()
+	return newClassToken(newNamedClassPart(`\d`, charclass.Digits)).withoutBrackets()
 }
 
 // Alphanumeric specifies digits and alphabetic characters.
@@ -31,7 +39,7 @@ func (CharsBaseDialect) Digits() ClassToken {
 //
 // Regex: `[[:alnum:]]`.
 func (CharsBaseDialect) Alphanumeric() ClassToken {
-	return newClassToken(helper.StringToken(`[:alnum:]`))
+	return newClassToken(newPOSIXClassPart("alnum"))
 }
 
 // Alphabetic specifies alphabetic lowercased and uppercased characters.
@@ -39,14 +47,14 @@ func (CharsBaseDialect) Alphanumeric() ClassToken {
 //
 // Regex: `[[:alpha:]]`.
 func (CharsBaseDialect) Alphabetic() ClassToken {
-	return newClassToken(helper.StringToken(`[:alpha:]`))
+	return newClassToken(newPOSIXClassPart("alpha"))
 }
 
 // ASCII only characters. It is an alias to [\x00-\x7F].
 //
 // Regex: `[[:ascii:]]`.
 func (CharsBaseDialect) ASCII() ClassToken {
-	return newClassToken(helper.StringToken(`[:ascii:]`))
+	return newClassToken(newPOSIXClassPart("ascii"))
 }
 
 // Whitespace specfies blank characters.
@@ -54,28 +62,28 @@ func (CharsBaseDialect) ASCII() ClassToken {
 //
 // Regex: `\s`.
 func (CharsBaseDialect) Whitespace() ClassToken {
-	return newClassToken(helper.StringToken(`\s`)).withoutBrackets()
+	return newClassToken(newNamedClassPart(`\s`, charclass.Whitespace)).withoutBrackets()
 }
 
 // WordCharacter is an alias to [0-9A-Za-z_]. ASCII.
 //
 // Regex: `\w`.
 func (CharsBaseDialect) WordCharacter() ClassToken {
-	return newClassToken(helper.StringToken(`\w`)).withoutBrackets()
+	return newClassToken(newNamedClassPart(`\w`, charclass.Word)).withoutBrackets()
 }
 
 // Blank ASCII characters. It is an alias to [\t ].
 //
 // Regex: `[[:blank:]]`.
 func (CharsBaseDialect) Blank() ClassToken {
-	return newClassToken(helper.StringToken(`[:blank:]`))
+	return newClassToken(newPOSIXClassPart("blank"))
 }
 
 // Control characters. It is an alias to [\x00-\x1F\x7F]. ASCII.
 //
 // Regex: `[[:cntrl:]]`.
 func (CharsBaseDialect) Control() ClassToken {
-	return newClassToken(helper.StringToken(`[:cntrl:]`))
+	return newClassToken(newPOSIXClassPart("cntrl"))
 }
 
 // Graphical characters. ASCII.
@@ -83,70 +91,70 @@ func (CharsBaseDialect) Control() ClassToken {
 //
 // Regex: `[[:graph:]]`.
 func (CharsBaseDialect) Graphical() ClassToken {
-	return newClassToken(helper.StringToken(`[:graph:]`))
+	return newClassToken(newPOSIXClassPart("graph"))
 }
 
 // Lower cased ASCII characters. It is an alias to [a-z].
 //
 // Regex: `[[:lower:]]`.
 func (CharsBaseDialect) Lower() ClassToken {
-	return newClassToken(helper.StringToken(`[:lower:]`))
+	return newClassToken(newPOSIXClassPart("lower"))
 }
 
 // Printable ASCII characters. It is an alias to [ [:graph:]].
 //
 // Regex: `[[:print:]]`.
 func (CharsBaseDialect) Printable() ClassToken {
-	return newClassToken(helper.StringToken(`[:print:]`))
+	return newClassToken(newPOSIXClassPart("print"))
 }
 
 // Punctuation ASCII characters. It is an alias to [!-/:-@[-`{-~].
 //
 // Regex: `[[:punct:]]`.
 func (CharsBaseDialect) Punctuation() ClassToken {
-	return newClassToken(helper.StringToken(`[:punct:]`))
+	return newClassToken(newPOSIXClassPart("punct"))
 }
 
 // Upper case ASCII characters. It is an alias to [A-Z].
 //
 // Regex: `[[:upper:]]`.
 func (CharsBaseDialect) Upper() ClassToken {
-	return newClassToken(helper.StringToken(`[:upper:]`))
+	return newClassToken(newPOSIXClassPart("upper"))
 }
 
 // HexDigits ASCII characters. It is an alias to  [0-9A-Fa-f].
 //
 // Regex: `[[:xdigit:]]`.
 func (CharsBaseDialect) HexDigits() ClassToken {
-	return newClassToken(helper.StringToken(`[:xdigit:]`))
+	return newClassToken(newPOSIXClassPart("xdigit"))
 }
 
-// Begin of text by default or line if the flag EnableMultiline is set.
+// Begin of text by default or line if the flag Flags.Multiline is set.
 //
 // Regex: `^`.
 func (CharsBaseDialect) Begin() ClassToken {
-	return newClassToken(helper.ByteToken('^')).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`^`, dialect.KindBegin, nil)).withoutBrackets()
 }
 
-// Begin of text (even if the flag EnableMultiline is set)
+// Begin of text (even if the flag Flags.Multiline is set)
 //
 // Regex: `\A`.
 func (CharsBaseDialect) BeginOfText() ClassToken {
-	return newClassToken(helper.StringToken(`\A`)).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`\A`, dialect.KindBeginOfText, nil)).withoutBrackets()
 }
 
-// End of text or line if the flag EnableMultiline is set.
+// End of text or line if the flag Flags.Multiline is set.
 //
 // Regex: `$`.
 func (CharsBaseDialect) End() ClassToken {
-	return newClassToken(helper.ByteToken('$')).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`$`, dialect.KindEnd, nil)).withoutBrackets()
 }
 
-// End of text (even if the flag EnableMultiline is set).
+// End of text (even if the flag Flags.Multiline is set).
 //
 // Regex: `\z`.
 func (CharsBaseDialect) EndOfText() ClassToken {
-	return newClassToken(helper.StringToken(`\z`)).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`\z`, dialect.KindEndOfText, nil)).withoutBrackets()
 }
 
 // A word boundary for ACII words. Following positions count as word boundaries:
@@ -156,7 +164,7 @@ func (CharsBaseDialect) EndOfText() ClassToken {
 //
 // Regex: `\b`.
 func (CharsBaseDialect) ASCIIWordBoundary() ClassToken {
-	return newClassToken(helper.StringToken(`\b`)).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`\b`, dialect.KindWordBoundary, nil)).withoutBrackets()
 }
 
 // A non-word boundary:
@@ -164,14 +172,17 @@ func (CharsBaseDialect) ASCIIWordBoundary() ClassToken {
 //
 // Regex: `\B`.
 func (CharsBaseDialect) NotASCIIWordBoundary() ClassToken {
-	return newClassToken(helper.StringToken(`\B`)).withoutBrackets()
+	return newClassToken(newSpecialClassPart(`\B`, dialect.KindNoWordBoundary, nil)).withoutBrackets()
 }
 
-// Any character, possibly including newline if the flag AnyIncludeNewLine() is set.
+// Any character, possibly including newline if the flag Flags.AnyIncludeNewLine is set.
 //
 // Regex: `.`.
 func (CharsBaseDialect) Any() ClassToken {
-	return newClassToken(helper.ByteToken('.')).withoutBrackets()
+	// Inside brackets it matches a dot.
+	return newClassToken(
+		newSpecialClassPart(`.`, dialect.KindAnyChar, []rune{'.', '.'}),
+	).withoutBrackets()
 }
 
 // Runes create a class that contains defined runes.
@@ -182,7 +193,7 @@ func (CharsBaseDialect) Any() ClassToken {
 //	Runes("a") // == Chars.Single('a')
 //	Runes("ab") // == Common.Class(Chars.Single('a'), Chars.Single('b'))
 //
-// Regex: `[abc]`.
+// Regex: `[a-c]`.
 func (CharsBaseDialect) Runes(val string) ClassToken {
 	// It is not accurate capacity, but enough.
 	tokens := make([]dialect.Token, 0, len(val))
@@ -198,12 +209,93 @@ func (CharsBaseDialect) Runes(val string) ClassToken {
 	return classToken
 }
 
-// Range of characters.
-// The input is not validated.
+// Range of characters. The rune from should not be greater than to,
+// otherwise ErrInvalidRange is reported. Endpoints are escaped the same
+// way as in Chars.Single, so any runes are safe to use. A range of
+// one rune is the same as Chars.Single.
 //
 // Regex: `[a-z]`.
 func (CharsBaseDialect) Range(from rune, to rune) ClassToken {
-	return newClassToken(helper.StringToken("%c-%c", from, to))
+	if err := validateRange("Chars.Range", from, to); err != nil {
+		return newErrorClassToken(err)
+	}
+
+	if from == to {
+		return Chars.Single(from)
+	}
+
+	return newClassToken(newRangeClassPart(
+		escapeRune(from)+"-"+escapeRune(to), "", []rune{from, to},
+	))
+}
+
+// Ranges of characters. It is useful for sets, that are not defined in
+// `unicode.Categories` or `unicode.Scripts`. Overlapping ranges are
+// merged. Reversed or empty ranges are reported as ErrInvalidRange.
+//
+// Example usage:
+//
+//	Chars.Ranges([]unicode.Range32{{Lo: 'a', Hi: 'f', Stride: 1}, {Lo: '0', Hi: '9', Stride: 1}})
+//
+// Regex: `[0-9a-f]`.
+func (CharsBaseDialect) Ranges(ranges []unicode.Range32) ClassToken {
+	return rangeTableClassToken("Chars.Ranges", &unicode.RangeTable{R32: ranges})
+}
+
+// RangeTable creates a class of all runes of the table. Unlike
+// Chars.Unicode it accepts any tables, but it always expands them.
+// Reversed or empty tables are reported as ErrInvalidRange.
+//
+// Example usage:
+//
+//	Chars.RangeTable(unicode.ASCII_Hex_Digit)
+//
+// Regex: `[0-9A-Fa-f]`.
+func (CharsBaseDialect) RangeTable(table *unicode.RangeTable) ClassToken {
+	return rangeTableClassToken("Chars.RangeTable", table)
+}
+
+func rangeTableClassToken(method string, table *unicode.RangeTable) ClassToken {
+	if table == nil {
+		return newErrorClassToken(fmt.Errorf("%w: %s: table is nil", ErrInvalidRange, method))
+	}
+
+	for _, r := range table.R16 {
+		if err := validateRange(method, rune(r.Lo), rune(r.Hi)); err != nil {
+			return newErrorClassToken(err)
+		}
+	}
+
+	for _, r := range table.R32 {
+		if err := validateRange(method, rune(r.Lo), rune(r.Hi)); err != nil {
+			return newErrorClassToken(err)
+		}
+	}
+
+	ranges := charclass.FromTable(table)
+	if len(ranges) == 0 {
+		return newErrorClassToken(fmt.Errorf("%w: %s: no runes", ErrInvalidRange, method))
+	}
+
+	return newRangesClassToken(ranges)
+}
+
+// validateRange checks that runes are valid and not reversed.
+func validateRange(method string, from rune, to rune) error {
+	switch {
+	case from < 0 || to > unicode.MaxRune:
+		return fmt.Errorf(
+			"%w: %s(%q, %q): runes should be in [0, unicode.MaxRune]",
+			ErrInvalidRange, method, from, to,
+		)
+	case from > to:
+		return fmt.Errorf(
+			"%w: %s(%q, %q): from is greater than to",
+			ErrInvalidRange, method, from, to,
+		)
+	default:
+		return nil
+	}
 }
 
 // Single character. It supports not ascii characters.
@@ -211,33 +303,37 @@ func (CharsBaseDialect) Range(from rune, to rune) ClassToken {
 //
 // Regex: `r`, `\\xHEX_CODE`, or  `\\x{HEX_CODE}`.
 func (CharsBaseDialect) Single(r rune) ClassToken {
+	return newClassToken(newLiteralClassPart(escapeRune(r), r)).withoutBrackets()
+}
+
+// escapeRune returns the rune, that is safe to use inside and outside
+// of brackets.
+func escapeRune(r rune) string {
 	// Minus can be a special case in classes.
 	if r < unicode.MaxASCII && unicode.IsPrint(r) && r != '-' && r != '%' {
-		return newClassToken(
-			helper.StringToken(regexp.QuoteMeta(string(r))),
-		).withoutBrackets()
+		return regexp.QuoteMeta(string(r))
 	}
 
 	hexValue := strings.ToUpper(strconv.FormatInt(int64(r), 16))
 
 	if len(hexValue) == 2 {
-		return newClassToken(
-			helper.StringToken("\\x" + hexValue),
-		).withoutBrackets()
+		return "\\x" + hexValue
 	}
 
-	return newClassToken(
-		helper.StringToken("\\x{%s}", hexValue),
-	).withoutBrackets()
+	return "\\x{" + hexValue + "}"
 }
 
-// Unicode class. It supports *unicode.RangeTable that is defined
-// in `unicode.Categories` or `unicode.Scripts`.
-// The input is not validated.
+// Unicode class. Tables of `unicode.Categories` and `unicode.Scripts`
+// are written by their names. Other tables, for example from
+// `unicode.Properties` or merged by `rangetable.Merge`, are written by
+// the name of the equal category or script, if there is one, otherwise
+// they are expanded to a bracket expression, because RE2 doesn't support
+// property names. Empty tables are reported as ErrUnknownUnicodeTable.
 //
 // Example usage:
 //
-//	Chars.Unicode(unicode.Greek)
+//	Chars.Unicode(unicode.Greek)      // \p{Greek}
+//	Chars.Unicode(unicode.White_Space) // [\x{9}-\x{D} \x85\xA0\x{1680}...]
 //
 // Regex: `\p{Greek}`.
 func (d CharsBaseDialect) Unicode(table *unicode.RangeTable) ClassToken {
@@ -253,13 +349,25 @@ func (d CharsBaseDialect) Unicode(table *unicode.RangeTable) ClassToken {
 		}
 	}
 
-	return newClassToken(helper.StringToken("")).withoutBrackets()
+	ranges := charclass.FromTable(table)
+	if len(ranges) == 0 {
+		return newErrorClassToken(fmt.Errorf(
+			"%w: Chars.Unicode: table is empty",
+			ErrUnknownUnicodeTable,
+		))
+	}
+
+	if name, ok := unicodeNameOf(ranges); ok {
+		return d.UnicodeByName(name)
+	}
+
+	return newRangesClassToken(ranges)
 }
 
 // UnicodeByName class. It is alternative to Chars.Unicode, but accepts
 // name of the RangeTable. Unicode character classes are those in
-// unicode.Categories and unicode.Scripts.
-// The input is not validated.
+// unicode.Categories and unicode.Scripts. Other names are reported
+// as ErrUnknownUnicodeTable.
 //
 // Example usage:
 //
@@ -267,5 +375,79 @@ func (d CharsBaseDialect) Unicode(table *unicode.RangeTable) ClassToken {
 //
 // Regex: `\p{Greek}`.
 func (CharsBaseDialect) UnicodeByName(name string) ClassToken {
-	return newClassToken(helper.StringToken(`\p{%s}`, name)).withoutBrackets()
+	_, isCategory := unicode.Categories[name]
+	_, isScript := unicode.Scripts[name]
+
+	if !isCategory && !isScript && name != unicodeAnyName {
+		return newErrorClassToken(fmt.Errorf(
+			"%w: Chars.UnicodeByName(%q)",
+			ErrUnknownUnicodeTable, name,
+		))
+	}
+
+	value := `\p{` + name + `}`
+
+	return newClassToken(
+		newNamedClassPart(value, unicodeRanges(name)),
+	).withoutBrackets()
+}
+
+// newPOSIXClassPart creates a part of ASCII class: `[:alpha:]`.
+func newPOSIXClassPart(name string) classPart {
+	return newNamedClassPart("[:"+name+":]", charclass.POSIX[name])
+}
+
+// namedUnicodeTables are ranges of `unicode.Categories` and
+// `unicode.Scripts` sorted by names. They are computed on the first use.
+var namedUnicodeTables struct {
+	once   sync.Once
+	names  []string
+	ranges [][]rune
+}
+
+// unicodeNameOf returns the name of the category or the script with
+// exactly the same runes.
+func unicodeNameOf(ranges []rune) (string, bool) {
+	tables := &namedUnicodeTables
+
+	tables.once.Do(func() {
+		for _, names := range [][]string{sortedKeys(unicode.Categories), sortedKeys(unicode.Scripts)} {
+			for _, name := range names {
+				tables.names = append(tables.names, name)
+				tables.ranges = append(tables.ranges, unicodeRanges(name))
+			}
+		}
+	})
+
+	for i, named := range tables.ranges {
+		if reflect.DeepEqual(named, ranges) {
+			return tables.names[i], true
+		}
+	}
+
+	return "", false
+}
+
+func sortedKeys(tables map[string]*unicode.RangeTable) []string {
+	keys := make([]string, 0, len(tables))
+	for key := range tables {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// unicodeRanges returns runes of the unicode table by its name.
+func unicodeRanges(name string) []rune {
+	if name == unicodeAnyName {
+		return []rune{0, unicode.MaxRune}
+	}
+
+	if table, ok := unicode.Categories[name]; ok {
+		return charclass.FromTable(table)
+	}
+
+	return charclass.FromTable(unicode.Scripts[name])
 }
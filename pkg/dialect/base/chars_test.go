@@ -122,6 +122,57 @@ func TestRexChars_base(t *testing.T) {
 		Name:     "range_digits",
 		Chain:    []dialect.Token{base.Chars.Range('0', '9')},
 		Expected: `[0-9]`,
+	}, {
+		Name:     "range_escaped",
+		Chain:    []dialect.Token{base.Chars.Range('-', ']')},
+		Expected: `[\x2D-\]]`,
+	}, {
+		Name:     "range_caret_backslash",
+		Chain:    []dialect.Token{base.Chars.Range('\\', '^')},
+		Expected: `[\\-\^]`,
+	}, {
+		Name:     "range_non_printable",
+		Chain:    []dialect.Token{base.Chars.Range(0, ' ')},
+		Expected: `[\x{0}- ]`,
+	}, {
+		Name:     "range_same",
+		Chain:    []dialect.Token{base.Chars.Range('a', 'a')},
+		Expected: `a`,
+	}, {
+		Name:     "range_same_escaped",
+		Chain:    []dialect.Token{base.Chars.Range('.', '.')},
+		Expected: `\.`,
+	}, {
+		Name:     "range_same_in_class",
+		Chain:    []dialect.Token{base.Common.Class(base.Chars.Range('-', '-'), base.Chars.Digits())},
+		Expected: `[\d\x2D]`,
+	}, {
+		Name: "ranges",
+		Chain: []dialect.Token{base.Chars.Ranges([]unicode.Range32{
+			{Lo: 'a', Hi: 'f', Stride: 1},
+			{Lo: '0', Hi: '9', Stride: 1},
+			{Lo: 'c', Hi: 'h', Stride: 1},
+		})},
+		Expected: `[0-9a-h]`,
+	}, {
+		Name: "ranges_stride",
+		Chain: []dialect.Token{base.Chars.Ranges([]unicode.Range32{
+			{Lo: 'a', Hi: 'e', Stride: 2},
+		})},
+		Expected: `[ace]`,
+	}, {
+		Name:     "range_table",
+		Chain:    []dialect.Token{base.Chars.RangeTable(unicode.ASCII_Hex_Digit)},
+		Expected: `[0-9A-Fa-f]`,
+	}, {
+		Name: "range_table_in_class",
+		Chain: []dialect.Token{base.Common.Class(
+			base.Chars.RangeTable(&unicode.RangeTable{
+				R16: []unicode.Range16{{Lo: '-', Hi: '-', Stride: 1}},
+			}),
+			base.Chars.Digits(),
+		)},
+		Expected: `[\d\x2D]`,
 	}}.Run(t)
 }
 
@@ -135,13 +186,36 @@ func TestRexChars_unicode(t *testing.T) {
 		Chain:    []dialect.Token{base.Chars.Unicode(unicode.Cc)},
 		Expected: `\p{Cc}`,
 	}, {
-		Name: "unicode_invalid",
+		Name: "unicode_copy_of_script",
 		Chain: []dialect.Token{base.Chars.Unicode(&unicode.RangeTable{
-			R16:         nil,
-			R32:         nil,
-			LatinOffset: 0,
+			R16:         unicode.Greek.R16,
+			R32:         unicode.Greek.R32,
+			LatinOffset: unicode.Greek.LatinOffset,
 		})},
-		Expected: ``,
+		Expected: `\p{Greek}`,
+	}, {
+		Name:     "unicode_property",
+		Chain:    []dialect.Token{base.Chars.Unicode(unicode.Hyphen)},
+		Expected: `[\x2D\xAD\x{58A}\x{1806}\x{2010}\x{2011}\x{2E17}\x{30FB}\x{FE63}\x{FF0D}\x{FF65}]`,
+	}, {
+		Name:     "unicode_property_by_map",
+		Chain:    []dialect.Token{base.Chars.Unicode(unicode.Properties["ASCII_Hex_Digit"])},
+		Expected: `[0-9A-Fa-f]`,
+	}, {
+		Name: "unicode_custom_table",
+		Chain: []dialect.Token{base.Chars.Unicode(&unicode.RangeTable{
+			R16: []unicode.Range16{{Lo: 'a', Hi: 'z', Stride: 1}, {Lo: ']', Hi: ']', Stride: 1}},
+		})},
+		Expected: `[\]a-z]`,
+	}, {
+		Name: "unicode_custom_table_in_class",
+		Chain: []dialect.Token{base.Common.Class(
+			base.Chars.Unicode(&unicode.RangeTable{
+				R16: []unicode.Range16{{Lo: 'a', Hi: 'f', Stride: 1}},
+			}),
+			base.Chars.Digits(),
+		)},
+		Expected: `[\da-f]`,
 	}, {
 		Name:     "unicode_by_name_greek",
 		Chain:    []dialect.Token{base.Chars.UnicodeByName("Greek")},
@@ -165,13 +239,13 @@ func TestRexChars_runes(t *testing.T) {
 		Chain: []dialect.Token{
 			base.Chars.Runes("abc"),
 		},
-		Expected: `[abc]`,
+		Expected: `[a-c]`,
 	}, {
 		Name: "escaped",
 		Chain: []dialect.Token{
 			base.Chars.Runes(".+"),
 		},
-		Expected: `[\.\+]`,
+		Expected: `[\+\.]`,
 	}, {
 		Name: "unicode",
 		Chain: []dialect.Token{
@@ -198,18 +272,66 @@ func TestRexChars_runes(t *testing.T) {
 				base.Chars.Runes("abc"),
 			),
 		},
-		Expected: `[abc]`,
+		Expected: `[a-c]`,
 	}, {
 		Name: "CanBeRepetable",
 		Chain: []dialect.Token{
 			base.Chars.Runes("abc").Repeat().OneOrMore(),
 		},
-		Expected: `[abc]+`,
+		Expected: `[a-c]+`,
 	}, {
 		Name: "Punctuation",
 		Chain: []dialect.Token{
 			base.Chars.Runes("!#$%&'*+-/=?^_`{|}~"),
 		},
-		Expected: "[!#\\$\\x25&'\\*\\+\\x2D/=\\?\\^_`\\{\\|\\}~]",
+		Expected: "[!#-'\\*\\+\\x2D/=\\?\\^-`\\{-~]",
+	}}.Run(t)
+}
+
+func TestRexChars_errors(t *testing.T) {
+	test.RexErrTestCasesSlice{{
+		Name:  "range_reversed",
+		Chain: []dialect.Token{base.Chars.Range('z', 'a')},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "range_reversed_in_class",
+		Chain: []dialect.Token{base.Common.Class(base.Chars.Range('9', '0'))},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "range_negative",
+		Chain: []dialect.Token{base.Chars.Range(-1, 'a')},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "range_too_large",
+		Chain: []dialect.Token{base.Chars.Range('a', unicode.MaxRune+1)},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "ranges_reversed",
+		Chain: []dialect.Token{base.Chars.Ranges([]unicode.Range32{{Lo: 'z', Hi: 'a', Stride: 1}})},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "ranges_empty",
+		Chain: []dialect.Token{base.Chars.Ranges(nil)},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "range_table_nil",
+		Chain: []dialect.Token{base.Chars.RangeTable(nil)},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name: "unicode_unknown_table",
+		Chain: []dialect.Token{base.Chars.Unicode(&unicode.RangeTable{
+			R16:         nil,
+			R32:         nil,
+			LatinOffset: 0,
+		})},
+		Err: base.ErrUnknownUnicodeTable,
+	}, {
+		Name:  "unicode_nil_table",
+		Chain: []dialect.Token{base.Chars.Unicode(nil)},
+		Err:   base.ErrUnknownUnicodeTable,
+	}, {
+		Name:  "unicode_by_name_unknown",
+		Chain: []dialect.Token{base.Chars.UnicodeByName("Unknown")},
+		Err:   base.ErrUnknownUnicodeTable,
 	}}.Run(t)
 }
@@ -1,6 +1,10 @@
 package base
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/charclass"
 	"github.com/hedhyw/rex/internal/helper"
 	"github.com/hedhyw/rex/pkg/dialect"
 )
@@ -20,6 +24,122 @@ func newClassToken(classTokens ...dialect.Token) ClassToken {
 	}
 }
 
+// classPart is a single element of ClassToken with a known structure.
+type classPart struct {
+	value string
+	node  dialect.AST
+	// ranges of the part inside brackets. Nil, if the part can't be
+	// placed inside brackets.
+	ranges []rune
+}
+
+// newLiteralClassPart creates a part that matches a single rune.
+func newLiteralClassPart(value string, r rune) classPart {
+	node := dialect.NewAST(dialect.KindLiteral)
+	node.Value = string(r)
+
+	return classPart{
+		value:  value,
+		node:   *node,
+		ranges: []rune{r, r},
+	}
+}
+
+// newRangeClassPart creates a part that matches any rune from ranges.
+// The name is set for predefined classes.
+func newRangeClassPart(value string, name string, ranges []rune) classPart {
+	node := dialect.NewAST(dialect.KindClass)
+	node.Value = name
+	node.Ranges = ranges
+
+	return classPart{
+		value:  value,
+		node:   *node,
+		ranges: ranges,
+	}
+}
+
+// newNamedClassPart creates a part of a predefined class.
+func newNamedClassPart(name string, ranges []rune) classPart {
+	return newRangeClassPart(name, name, ranges)
+}
+
+// newSpecialClassPart creates a part that doesn't match runes, like
+// anchors. Inside brackets it matches given ranges.
+func newSpecialClassPart(value string, kind dialect.Kind, ranges []rune) classPart {
+	return classPart{
+		value:  value,
+		node:   *dialect.NewAST(kind),
+		ranges: ranges,
+	}
+}
+
+// not returns the negated form of the predefined class or the boundary.
+// It returns false, if the part doesn't have such a form.
+func (cp classPart) not() (classPart, bool) {
+	switch cp.node.Kind {
+	case dialect.KindWordBoundary:
+		return newSpecialClassPart(`\B`, dialect.KindNoWordBoundary, nil), true
+	case dialect.KindNoWordBoundary:
+		return newSpecialClassPart(`\b`, dialect.KindWordBoundary, nil), true
+	case dialect.KindClass:
+		if cp.node.Negated {
+			return newNamedClassPart(cp.node.Value, cp.node.Ranges), true
+		}
+	default:
+		return cp, false
+	}
+
+	value := negatedClassName(cp.node.Value)
+	if value == "" {
+		return cp, false
+	}
+
+	node := cp.node
+	node.Negated = true
+
+	return classPart{
+		value:  value,
+		node:   node,
+		ranges: charclass.Negate(cp.ranges),
+	}, true
+}
+
+// negatedClassName returns the negated form of the predefined class:
+// `\D`, `\P{Greek}` or `[:^alpha:]`. It returns an empty string, if the
+// class is not predefined.
+func negatedClassName(name string) string {
+	switch {
+	case name == `\d`, name == `\s`, name == `\w`:
+		return strings.ToUpper(name)
+	case strings.HasPrefix(name, `\p{`):
+		return `\P{` + strings.TrimPrefix(name, `\p{`)
+	case strings.HasPrefix(name, "[:"):
+		return "[:^" + strings.TrimPrefix(name, "[:")
+	default:
+		return ""
+	}
+}
+
+// WriteTo implements dialect.Token interface.
+func (cp classPart) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	return w.WriteString(cp.value)
+}
+
+// AST implements dialect.Node interface.
+func (cp classPart) AST() *dialect.AST {
+	node := cp.node
+	node.Ranges = append([]rune(nil), node.Ranges...)
+
+	return &node
+}
+
+// newErrorClassToken creates a class token that writes nothing
+// and reports the error.
+func newErrorClassToken(err error) ClassToken {
+	return newClassToken(helper.ErrorToken(err)).withoutBrackets()
+}
+
 // Repeat class token.
 func (ct ClassToken) Repeat() Repetition {
 	if len(ct.classTokens) == 0 {
@@ -30,10 +150,58 @@ func (ct ClassToken) Repeat() Repetition {
 }
 
 // Unwrap implements dialect.ClassToken.
+//
+// Excluded classes with known runes are unwrapped to runes, that they
+// match, so `[^0-9]` keeps its meaning inside other classes.
 func (ct ClassToken) Unwrap() dialect.ClassToken {
+	if ct.exclude && ct.brackets {
+		if ranges, ok := ct.ranges(); ok {
+			ranges = charclass.Negate(ranges)
+
+			return newClassToken(newRangeClassPart(formatClassRanges(ranges), "", ranges)).withoutBrackets()
+		}
+	}
+
 	return ct.withoutBrackets()
 }
 
+// Not returns the class of runes, that are not in the class. Predefined
+// classes are written in their negated forms, other classes are written
+// as `[^...]`. The result can be used inside Common.Class. Anchors and
+// Chars.Any can't be negated, they are reported as ErrInvalidClass.
+//
+// Example usage:
+//
+//	Chars.Digits().Not()                 // \D
+//	Chars.UnicodeByName("Greek").Not()   // \P{Greek}
+//	Chars.Alphabetic().Not()             // [[:^alpha:]]
+//	Chars.Runes("ab").Not()              // [^ab]
+//	Common.Class(Chars.Upper().Not(), Chars.Digits()) // [^[:upper:]], digits are not upper
+func (ct ClassToken) Not() ClassToken {
+	if len(ct.classTokens) == 0 {
+		return ct
+	}
+
+	if part, ok := ct.classTokens[0].(classPart); ok && len(ct.classTokens) == 1 && !ct.exclude {
+		if negated, ok := part.not(); ok {
+			ct.classTokens = []dialect.Token{negated}
+
+			return ct
+		}
+
+		if part.node.Kind != dialect.KindClass && part.node.Kind != dialect.KindLiteral {
+			return newErrorClassToken(fmt.Errorf(
+				"%w: %s can't be negated", ErrInvalidClass, part.node.Kind,
+			))
+		}
+	}
+
+	ct.brackets = true
+	ct.exclude = !ct.exclude
+
+	return ct
+}
+
 func (ct ClassToken) withoutBrackets() ClassToken {
 	ct.brackets = false
 
@@ -46,12 +214,81 @@ func (ct ClassToken) withExclude() ClassToken {
 	return ct
 }
 
+// AST implements dialect.Node interface.
+//
+// The class is represented as KindRaw if it contains tokens with unknown
+// runes, for example Common.Raw.
+func (ct ClassToken) AST() *dialect.AST {
+	if len(ct.classTokens) == 0 {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	if part, ok := ct.classTokens[0].(classPart); ok && !ct.brackets && len(ct.classTokens) == 1 {
+		return part.AST()
+	}
+
+	ranges, ok := ct.ranges()
+	if !ok || !ct.brackets {
+		return dialect.ASTOf(helper.TokenFunc(ct.WriteTo))
+	}
+
+	node := dialect.NewAST(dialect.KindClass)
+	node.Ranges = ranges
+	node.Negated = ct.exclude
+
+	if part, ok := ct.classTokens[0].(classPart); ok && len(ct.classTokens) == 1 && part.node.Kind == dialect.KindClass {
+		node.Value = part.node.Value
+		node.Ranges = append([]rune(nil), part.node.Ranges...)
+		node.Negated = ct.exclude != part.node.Negated
+	} else if ct.partsCount() > 1 {
+		node.Value = shorthandName(ranges)
+	}
+
+	return node
+}
+
+// ranges returns runes of the class. It returns false, if any element
+// of the class is unknown.
+func (ct ClassToken) ranges() ([]rune, bool) {
+	sets := make([][]rune, 0, len(ct.classTokens))
+
+	for _, tok := range ct.classTokens {
+		switch tok := tok.(type) {
+		case classPart:
+			if tok.ranges == nil {
+				return nil, false
+			}
+
+			sets = append(sets, tok.ranges)
+		case ClassToken:
+			ranges, ok := tok.ranges()
+			if !ok {
+				return nil, false
+			}
+
+			sets = append(sets, ranges)
+		default:
+			return nil, false
+		}
+	}
+
+	return charclass.Union(sets...), true
+}
+
 // WriteTo implements dialect.Token interface.
+//
+// Classes of multiple parts with known runes are written in a canonical
+// form: ranges are merged and sorted, duplicates are dropped, and the
+// class is collapsed to a shorthand if it is equivalent.
 func (ct ClassToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 	if len(ct.classTokens) == 0 {
 		return 0, nil
 	}
 
+	if canonical, ok := ct.canonical(); ok {
+		return w.WriteString(canonical)
+	}
+
 	tokens := make([]dialect.Token, 0, 3+len(ct.classTokens))
 
 	if ct.brackets {
@@ -0,0 +1,154 @@
+package base
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// shorthands are predefined classes, that can replace equivalent
+// classes. The order defines priority.
+var shorthands = []struct {
+	name    string
+	negated string
+	ranges  []rune
+}{
+	{name: `\d`, negated: `\D`, ranges: charclass.Digits},
+	{name: `\s`, negated: `\S`, ranges: charclass.Whitespace},
+	{name: `\w`, negated: `\W`, ranges: charclass.Word},
+}
+
+// posixShorthands are POSIX classes that can replace equivalent classes.
+var posixShorthands = []string{
+	"alnum", "alpha", "ascii", "blank", "cntrl", "graph",
+	"lower", "print", "punct", "space", "upper", "xdigit",
+}
+
+// shorthandName returns a name of the predefined class as it is written
+// inside brackets. It returns an empty string if the class is not known.
+func shorthandName(ranges []rune) string {
+	for _, sh := range shorthands {
+		if reflect.DeepEqual(ranges, sh.ranges) {
+			return sh.name
+		}
+	}
+
+	for _, name := range posixShorthands {
+		if reflect.DeepEqual(ranges, charclass.POSIX[name]) {
+			return "[:" + name + ":]"
+		}
+	}
+
+	return ""
+}
+
+// partsCount returns a count of parts in the class including parts
+// of nested classes.
+func (ct ClassToken) partsCount() int {
+	var count int
+
+	for _, tok := range ct.classTokens {
+		if nested, ok := tok.(ClassToken); ok {
+			count += nested.partsCount()
+		} else {
+			count++
+		}
+	}
+
+	return count
+}
+
+// namedParts returns names and ranges of predefined classes, that the
+// class contains.
+func (ct ClassToken) namedParts() (names []string, ranges [][]rune) {
+	for _, tok := range ct.classTokens {
+		switch tok := tok.(type) {
+		case ClassToken:
+			nestedNames, nestedRanges := tok.namedParts()
+
+			names = append(names, nestedNames...)
+			ranges = append(ranges, nestedRanges...)
+		case classPart:
+			if tok.node.Kind == dialect.KindClass && tok.node.Value != "" {
+				names = append(names, tok.value)
+				ranges = append(ranges, tok.ranges)
+			}
+		}
+	}
+
+	return names, ranges
+}
+
+// canonical returns the class in the shortest form. It returns false,
+// if the class has a single part or unknown parts.
+func (ct ClassToken) canonical() (string, bool) {
+	if !ct.brackets || ct.partsCount() <= 1 {
+		return "", false
+	}
+
+	ranges, ok := ct.ranges()
+	if !ok {
+		return "", false
+	}
+
+	names, namedRanges := ct.namedParts()
+	canonical := renderClass(ranges, ct.exclude, names, namedRanges)
+
+	// The complement can be shorter: `[^0-9]` instead of `[\x{0}-/:-\x{10FFFF}]`.
+	if negated := charclass.Negate(ranges); len(negated) > 0 {
+		if complement := renderClass(negated, !ct.exclude, nil, nil); len(complement) < len(canonical) {
+			canonical = complement
+		}
+	}
+
+	return canonical, true
+}
+
+// renderClass returns the shortest class of the ranges. Names are
+// predefined classes, that can be used instead of namedRanges.
+func renderClass(ranges []rune, negated bool, names []string, namedRanges [][]rune) string {
+	for _, sh := range shorthands {
+		if reflect.DeepEqual(ranges, sh.ranges) {
+			if negated {
+				return sh.negated
+			}
+
+			return sh.name
+		}
+	}
+
+	inner := formatClassRanges(ranges)
+
+	if name := shorthandName(ranges); name != "" {
+		inner = name
+	} else if len(names) > 0 {
+		// Predefined classes can be shorter than their ranges.
+		rest := charclass.Subtract(ranges, namedRanges...)
+
+		if withNames := strings.Join(uniqueStrings(names), "") + formatClassRanges(rest); len(withNames) <= len(inner) {
+			inner = withNames
+		}
+	}
+
+	if negated {
+		return "[^" + inner + "]"
+	}
+
+	return "[" + inner + "]"
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	unique := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,125 @@
+package base
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// Union returns a class of runes, that are in the class or in any
+// of others.
+//
+// Example usage:
+//
+//	Chars.Digits().Union(Chars.Runes("abcdef")) // [0-9a-f]
+func (ct ClassToken) Union(others ...dialect.ClassToken) ClassToken {
+	return ct.combine(others, charclass.Union)
+}
+
+// Intersect returns a class of runes, that are in the class and in all
+// of others.
+//
+// Example usage:
+//
+//	Chars.Alphanumeric().Intersect(Chars.HexDigits()) // [0-9A-Fa-f]
+func (ct ClassToken) Intersect(others ...dialect.ClassToken) ClassToken {
+	return ct.combine(others, charclass.Intersect)
+}
+
+// Subtract returns a class of runes, that are in the class, but not in
+// any of others.
+//
+// Example usage:
+//
+//	Chars.Alphabetic().Subtract(Chars.Runes("aeiouAEIOU")) // [B-DF-HJ-NP-TV-Zb-df-hj-np-tv-z]
+func (ct ClassToken) Subtract(others ...dialect.ClassToken) ClassToken {
+	return ct.combine(others, func(sets ...[]rune) []rune {
+		return charclass.Subtract(sets[0], sets[1:]...)
+	})
+}
+
+// Negate returns a class of runes, that are not in the class.
+//
+// Example usage:
+//
+//	Chars.Digits().Negate() // [^0-9]
+func (ct ClassToken) Negate() ClassToken {
+	return ct.combine(nil, func(sets ...[]rune) []rune {
+		return charclass.Negate(sets[0])
+	})
+}
+
+func (ct ClassToken) combine(others []dialect.ClassToken, op func(sets ...[]rune) []rune) ClassToken {
+	sets := make([][]rune, 0, 1+len(others))
+
+	for _, tok := range append([]dialect.ClassToken{ct}, others...) {
+		ranges, err := classRanges(tok)
+		if err != nil {
+			return newErrorClassToken(err)
+		}
+
+		sets = append(sets, ranges)
+	}
+
+	return newRangesClassToken(op(sets...))
+}
+
+// classRanges returns runes, that the class matches.
+func classRanges(token dialect.ClassToken) ([]rune, error) {
+	if _, err := token.WriteTo(&strings.Builder{}); err != nil {
+		return nil, err
+	}
+
+	node := dialect.ASTOf(token)
+
+	switch {
+	case node.Kind == dialect.KindClass && node.Negated:
+		return charclass.Negate(node.Ranges), nil
+	case node.Kind == dialect.KindClass:
+		return node.Ranges, nil
+	case node.Kind == dialect.KindLiteral && len([]rune(node.Value)) == 1:
+		r := []rune(node.Value)[0]
+
+		return []rune{r, r}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s can't be used in class operations", ErrInvalidClass, node.Kind)
+	}
+}
+
+// newRangesClassToken creates the shortest class of the ranges.
+func newRangesClassToken(ranges []rune) ClassToken {
+	negated := charclass.Negate(ranges)
+
+	switch {
+	case len(ranges) == 0:
+		// Brackets can't be empty.
+		return newClassToken(newRangeClassPart(formatClassRanges(negated), "", negated)).withExclude()
+	case len(negated) > 0 && len(formatClassRanges(negated)) < len(formatClassRanges(ranges)):
+		return newClassToken(newRangeClassPart(formatClassRanges(negated), "", negated)).withExclude()
+	default:
+		return newClassToken(newRangeClassPart(formatClassRanges(ranges), "", ranges))
+	}
+}
+
+// formatClassRanges returns the content of brackets: `a-z0`.
+func formatClassRanges(ranges []rune) string {
+	var sb strings.Builder
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+
+		_, _ = sb.WriteString(escapeRune(lo))
+
+		if hi > lo+1 {
+			_ = sb.WriteByte('-')
+		}
+
+		if hi > lo {
+			_, _ = sb.WriteString(escapeRune(hi))
+		}
+	}
+
+	return sb.String()
+}
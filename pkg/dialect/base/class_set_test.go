@@ -0,0 +1,86 @@
+package base_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+func TestClassSet(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "Union",
+		Chain:    []dialect.Token{base.Chars.Digits().Union(base.Chars.Runes("abcdef"), base.Chars.Single('g'))},
+		Expected: `[0-9a-g]`,
+	}, {
+		Name:     "UnionOverlapping",
+		Chain:    []dialect.Token{base.Chars.Range('a', 'm').Union(base.Chars.Range('f', 'z'), base.Chars.Digits())},
+		Expected: `[0-9a-z]`,
+	}, {
+		Name:     "Intersect",
+		Chain:    []dialect.Token{base.Chars.Alphanumeric().Intersect(base.Chars.HexDigits())},
+		Expected: `[0-9A-Fa-f]`,
+	}, {
+		Name: "IntersectUnicode",
+		Chain: []dialect.Token{
+			base.Chars.Unicode(unicode.Greek).Intersect(base.Chars.Unicode(unicode.Lu), base.Chars.Range('Α', 'Γ')),
+		},
+		Expected: `[\x{391}-\x{393}]`,
+	}, {
+		Name:     "Subtract",
+		Chain:    []dialect.Token{base.Chars.Alphabetic().Subtract(base.Chars.Runes("aeiouAEIOU"))},
+		Expected: `[B-DF-HJ-NP-TV-Zb-df-hj-np-tv-z]`,
+	}, {
+		Name: "SubtractQuotes",
+		Chain: []dialect.Token{
+			base.Chars.Printable().Subtract(base.Chars.Runes(`"'`), base.Chars.Single('`')),
+		},
+		Expected: "[ !#-&\\(-_a-~]",
+	}, {
+		Name:     "SubtractPunctuation",
+		Chain:    []dialect.Token{base.Chars.Punctuation().Subtract(base.Chars.Single('-'), base.Chars.Single(']'))},
+		Expected: `[!-,\./:-@\[\\\^-` + "`" + `\{-~]`,
+	}, {
+		Name:     "Negate",
+		Chain:    []dialect.Token{base.Chars.Digits().Negate()},
+		Expected: `[^0-9]`,
+	}, {
+		Name:     "NegateNotClass",
+		Chain:    []dialect.Token{base.Common.NotClass(base.Chars.Digits()).Negate()},
+		Expected: `[0-9]`,
+	}, {
+		Name:     "SubtractAll",
+		Chain:    []dialect.Token{base.Chars.Digits().Subtract(base.Chars.Alphanumeric())},
+		Expected: `[^\x{0}-\x{10FFFF}]`,
+	}, {
+		Name: "UnicodeNegated",
+		Chain: []dialect.Token{
+			base.Chars.Unicode(unicode.Greek).Union(base.Chars.Digits()).
+				Negate().Negate().
+				Subtract(base.Chars.Unicode(unicode.Greek)),
+		},
+		Expected: `[0-9]`,
+	}, {
+		Name:     "NegatedResultInClass",
+		Chain:    []dialect.Token{base.Common.Class(base.Chars.Single('x'), base.Chars.Digits().Negate())},
+		Expected: `\D`,
+	}}.Run(t)
+}
+
+func TestClassSet_errors(t *testing.T) {
+	test.RexErrTestCasesSlice{{
+		Name:  "Any",
+		Chain: []dialect.Token{base.Chars.Any().Subtract(base.Chars.Digits())},
+		Err:   base.ErrInvalidClass,
+	}, {
+		Name:  "Begin",
+		Chain: []dialect.Token{base.Chars.Digits().Union(base.Chars.Begin())},
+		Err:   base.ErrInvalidClass,
+	}, {
+		Name:  "InvalidRange",
+		Chain: []dialect.Token{base.Chars.Digits().Union(base.Chars.Range('z', 'a'))},
+		Err:   base.ErrInvalidRange,
+	}}.Run(t)
+}
@@ -2,6 +2,7 @@ package base_test
 
 import (
 	"testing"
+	"unicode"
 
 	"github.com/hedhyw/rex/internal/test"
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -18,7 +19,7 @@ func TestRexClass(t *testing.T) {
 				base.Chars.Single('0'),
 			),
 		},
-		Expected: `[A-Z0]`,
+		Expected: `[0A-Z]`,
 	}, {
 		Name: "ClassInClass",
 		Chain: []dialect.Token{
@@ -29,7 +30,7 @@ func TestRexClass(t *testing.T) {
 				base.Chars.Single('0'),
 			),
 		},
-		Expected: `[A-Z0]`,
+		Expected: `[0A-Z]`,
 	}, {
 		Name: "RegularToken",
 		Chain: []dialect.Token{
@@ -59,6 +60,65 @@ func TestRexClass(t *testing.T) {
 			),
 		},
 		Expected: `[[:xdigit:]]`,
+	}, {
+		Name: "CanonicalDuplicates",
+		Chain: []dialect.Token{
+			base.Common.Class(
+				base.Chars.Digits(),
+				base.Chars.Range('0', '5'),
+				base.Chars.Single('a'),
+				base.Chars.Runes("abc"),
+			),
+		},
+		Expected: `[\da-c]`,
+	}, {
+		Name: "CanonicalShorthand",
+		Chain: []dialect.Token{
+			base.Common.Class(
+				base.Chars.Range('0', '5'),
+				base.Chars.Range('4', '9'),
+			),
+		},
+		Expected: `\d`,
+	}, {
+		Name: "CanonicalNegatedShorthand",
+		Chain: []dialect.Token{
+			base.Common.NotClass(
+				base.Chars.Range('a', 'z'),
+				base.Chars.Range('A', 'Z'),
+				base.Chars.Digits(),
+				base.Chars.Single('_'),
+			),
+		},
+		Expected: `\W`,
+	}, {
+		Name: "CanonicalPOSIX",
+		Chain: []dialect.Token{
+			base.Common.Class(
+				base.Chars.Range('a', 'z'),
+				base.Chars.Range('A', 'Z'),
+			),
+		},
+		Expected: `[[:alpha:]]`,
+	}, {
+		Name: "CanonicalNamedParts",
+		Chain: []dialect.Token{
+			base.Common.Class(
+				base.Chars.UnicodeByName("Greek"),
+				base.Chars.Single('_'),
+				base.Chars.UnicodeByName("Greek"),
+			),
+		},
+		Expected: `[\p{Greek}_]`,
+	}, {
+		Name: "CanonicalComplement",
+		Chain: []dialect.Token{
+			base.Common.Class(
+				base.Common.NotClass(base.Chars.Range('a', 'z')),
+				base.Chars.Single('b'),
+			),
+		},
+		Expected: `[^ac-z]`,
 	}, {
 		Name: "ClassEmpty",
 		Chain: []dialect.Token{
@@ -73,3 +133,99 @@ func TestRexClass(t *testing.T) {
 		Expected: ``,
 	}}.Run(t)
 }
+
+// nolint: funlen // Unit test.
+func TestRexClassNot(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "Digits",
+		Chain:    []dialect.Token{base.Chars.Digits().Not()},
+		Expected: `\D`,
+	}, {
+		Name:     "Whitespace",
+		Chain:    []dialect.Token{base.Chars.Whitespace().Not()},
+		Expected: `\S`,
+	}, {
+		Name:     "WordCharacter",
+		Chain:    []dialect.Token{base.Chars.WordCharacter().Not()},
+		Expected: `\W`,
+	}, {
+		Name:     "Unicode",
+		Chain:    []dialect.Token{base.Chars.Unicode(unicode.Greek).Not()},
+		Expected: `\P{Greek}`,
+	}, {
+		Name:     "POSIX",
+		Chain:    []dialect.Token{base.Chars.Alphabetic().Not()},
+		Expected: `[[:^alpha:]]`,
+	}, {
+		Name:     "DoubleNot",
+		Chain:    []dialect.Token{base.Chars.Upper().Not().Not()},
+		Expected: `[[:upper:]]`,
+	}, {
+		Name:     "WordBoundary",
+		Chain:    []dialect.Token{base.Chars.ASCIIWordBoundary().Not()},
+		Expected: `\B`,
+	}, {
+		Name:     "Single",
+		Chain:    []dialect.Token{base.Chars.Single('a').Not()},
+		Expected: `[^a]`,
+	}, {
+		Name:     "Range",
+		Chain:    []dialect.Token{base.Chars.Range('a', 'z').Not()},
+		Expected: `[^a-z]`,
+	}, {
+		Name:     "NotClass",
+		Chain:    []dialect.Token{base.Common.NotClass(base.Chars.Range('a', 'z')).Not()},
+		Expected: `[a-z]`,
+	}, {
+		Name:     "Combined",
+		Chain:    []dialect.Token{base.Common.Class(base.Chars.Runes("xy"), base.Chars.Digits()).Not()},
+		Expected: `[^\dxy]`,
+	}, {
+		Name: "InClass",
+		Chain: []dialect.Token{
+			base.Common.Class(base.Chars.Unicode(unicode.Greek).Not(), base.Chars.Single('α')),
+		},
+		Expected: `[\P{Greek}\x{3B1}]`,
+	}, {
+		Name: "POSIXInClass",
+		Chain: []dialect.Token{
+			base.Common.Class(base.Chars.Alphabetic().Not(), base.Chars.Digits()),
+		},
+		Expected: `[^[:alpha:]]`,
+	}, {
+		// The example from the documentation of ClassToken.Not.
+		Name: "DocumentationExample",
+		Chain: []dialect.Token{
+			base.Common.Class(base.Chars.Upper().Not(), base.Chars.Digits()),
+		},
+		Expected: `[^[:upper:]]`,
+	}, {
+		Name: "BracketedInClass",
+		Chain: []dialect.Token{
+			base.Common.Class(base.Chars.Runes("ab").Not(), base.Chars.Single('a')),
+		},
+		Expected: `[^b]`,
+	}, {
+		Name: "CollapsedInClass",
+		Chain: []dialect.Token{
+			base.Common.Class(base.Chars.Digits().Not(), base.Chars.Single('a')),
+		},
+		Expected: `\D`,
+	}}.Run(t)
+}
+
+func TestRexClassNot_errors(t *testing.T) {
+	test.RexErrTestCasesSlice{{
+		Name:  "Begin",
+		Chain: []dialect.Token{base.Chars.Begin().Not()},
+		Err:   base.ErrInvalidClass,
+	}, {
+		Name:  "Any",
+		Chain: []dialect.Token{base.Chars.Any().Not()},
+		Err:   base.ErrInvalidClass,
+	}, {
+		Name:  "InvalidRange",
+		Chain: []dialect.Token{base.Chars.Range('z', 'a').Not()},
+		Err:   base.ErrInvalidRange,
+	}}.Run(t)
+}
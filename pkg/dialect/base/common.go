@@ -1,7 +1,14 @@
 package base
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/hedhyw/rex/internal/helper"
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -35,7 +42,127 @@ func (CommonBaseDialect) RawVerbose(raw string) RawToken {
 
 // Text appends the text, and escapes all regular expression metacharacters.
 func (CommonBaseDialect) Text(text string) dialect.Token {
-	return helper.StringToken(regexp.QuoteMeta(text))
+	return textToken{value: text}
+}
+
+// OneOf matches one of values. Values are escaped like in Common.Text.
+// Longer values go first, so the longest value is matched, and
+// duplicates are removed. At least one value is required, otherwise
+// ErrNoValues is reported.
+//
+// Example usage:
+//
+//	Common.OneOf("a", "a.b", "ab", "a") // a\.b|ab|a
+func (CommonBaseDialect) OneOf(values ...string) dialect.Token {
+	return oneOf("Common.OneOf", values)
+}
+
+func oneOf(method string, values []string) dialect.Token {
+	unique := uniqueStrings(values)
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		if len(unique[i]) != len(unique[j]) {
+			return len(unique[i]) > len(unique[j])
+		}
+
+		return unique[i] < unique[j]
+	})
+
+	tokens := make([]dialect.Token, 0, len(unique))
+	for _, value := range unique {
+		tokens = append(tokens, textToken{value: value})
+	}
+
+	switch len(tokens) {
+	case 0:
+		return helper.ErrorToken(fmt.Errorf("%w: %s", ErrNoValues, method))
+	case 1:
+		return tokens[0]
+	default:
+		return CompositToken{tokens: tokens}
+	}
+}
+
+// OneOfFromReader matches one of values from the reader. Each line is
+// a value, empty lines are ignored. See Common.OneOf.
+//
+// Reading errors are reported by the token. The reader should contain
+// at least one value, otherwise ErrNoValues is reported.
+func (CommonBaseDialect) OneOfFromReader(r io.Reader) dialect.Token {
+	var values []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSuffix(scanner.Text(), "\r"); line != "" {
+			values = append(values, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return helper.ErrorToken(fmt.Errorf("Common.OneOfFromReader: %w", err))
+	}
+
+	return oneOf("Common.OneOfFromReader", values)
+}
+
+// Repeat any token. The token is wrapped in a non-captured group, if
+// it is not a single character, a class or a group, so the repetition
+// is applied to the whole token. Flags and empty tokens can't be
+// repeated, they are reported as ErrInvalidRepetition.
+//
+// Example usage:
+//
+//	Common.Repeat(Common.Text("ab")).OneOrMore()  // (?:ab)+
+//	Common.Repeat(Common.Text("a")).OneOrMore()   // a+
+//	Common.Repeat(Common.Raw(`[ab]`)).OneOrMore() // [ab]+
+func (CommonBaseDialect) Repeat(token dialect.Token) Repetition {
+	if repetition, ok := token.(Repetition); ok {
+		return repetition.Repeat()
+	}
+
+	var sb strings.Builder
+
+	// The error is reported by the repetition.
+	if _, err := token.WriteTo(&sb); err != nil {
+		return newRepetition(token)
+	}
+
+	node := dialect.ASTOf(token)
+
+	if sb.Len() == 0 || isEmptyAST(node) {
+		return newRepetition(nil).withError(
+			fmt.Errorf("%w: Common.Repeat: empty token", ErrInvalidRepetition),
+		)
+	}
+
+	if helper.PrecedenceOf(node) == helper.PrecAtom {
+		return newRepetition(token)
+	}
+
+	return newRepetition(Group.NonCaptured(token))
+}
+
+// isEmptyAST returns true if the node matches only an empty string
+// without conditions, for example, flags or an empty text.
+func isEmptyAST(node *dialect.AST) bool {
+	switch node.Kind {
+	case dialect.KindEmpty, dialect.KindFlags:
+		return true
+	case dialect.KindRaw:
+		re, err := syntax.Parse(node.Value, syntax.Perl)
+
+		return err == nil && re.Op == syntax.OpEmptyMatch
+	case dialect.KindConcat:
+		for _, sub := range node.Sub {
+			if !isEmptyAST(sub) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
 }
 
 // Class specifies the class of characters.
@@ -48,6 +175,31 @@ func (CommonBaseDialect) NotClass(tokens ...dialect.ClassToken) ClassToken {
 	return newClassToken(unwrapClassTokens(tokens)...).withExclude()
 }
 
+// BackRef matches the same text as the captured group matched. The group
+// is referenced by its name or by its index, if nameOrIndex contains only
+// digits. Invalid names are reported as ErrInvalidGroupName.
+//
+// It is not supported by RE2, see RegExp.StringFor.
+//
+// Example usage:
+//
+//	Common.BackRef("quote") // \k<quote>
+//	Common.BackRef("1")     // (?:\1)
+func (CommonBaseDialect) BackRef(nameOrIndex string) dialect.Token {
+	index, err := strconv.Atoi(nameOrIndex)
+
+	switch {
+	case err == nil && index > 0:
+		return backRefToken{name: "", index: index, err: nil}
+	case err == nil, !helper.IsWord(nameOrIndex):
+		return backRefToken{name: nameOrIndex, index: 0, err: fmt.Errorf(
+			"%w: Common.BackRef(%q)", ErrInvalidGroupName, nameOrIndex,
+		)}
+	default:
+		return backRefToken{name: nameOrIndex, index: 0, err: nil}
+	}
+}
+
 func unwrapClassTokens(classTokens []dialect.ClassToken) []dialect.Token {
 	tokens := make([]dialect.Token, 0, len(classTokens))
 
@@ -57,3 +209,54 @@ func unwrapClassTokens(classTokens []dialect.ClassToken) []dialect.Token {
 
 	return tokens
 }
+
+// textToken matches the text as is.
+type textToken struct {
+	value string
+}
+
+// WriteTo implements dialect.Token interface.
+func (tt textToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	return w.WriteString(regexp.QuoteMeta(tt.value))
+}
+
+// AST implements dialect.Node interface.
+func (tt textToken) AST() *dialect.AST {
+	if tt.value == "" {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	node := dialect.NewAST(dialect.KindLiteral)
+	node.Value = tt.value
+
+	return node
+}
+
+// backRefToken refers to a captured group by its name or index.
+type backRefToken struct {
+	name  string
+	index int
+	err   error
+}
+
+// WriteTo implements dialect.Token interface.
+func (bt backRefToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	switch {
+	case bt.err != nil:
+		return 0, bt.err
+	case bt.name != "":
+		return w.WriteString(`\k<` + bt.name + `>`)
+	default:
+		// The group delimits the index from following digits: `(?:\1)0`.
+		return w.WriteString(`(?:\` + strconv.Itoa(bt.index) + `)`)
+	}
+}
+
+// AST implements dialect.Node interface.
+func (bt backRefToken) AST() *dialect.AST {
+	node := dialect.NewAST(dialect.KindBackRef)
+	node.Name = bt.name
+	node.Index = bt.index
+
+	return node
+}
@@ -1,7 +1,10 @@
 package base_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/hedhyw/rex/internal/test"
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -33,5 +36,180 @@ func TestRexCommon(t *testing.T) {
 		Name:     "Text_Escaped",
 		Chain:    []dialect.Token{base.Common.Text(`^[A-Z]+$`)},
 		Expected: `\^\[A-Z\]\+\$`,
+	}, {
+		Name:     "BackRef_Name",
+		Chain:    []dialect.Token{base.Common.BackRef("quote")},
+		Expected: `\k<quote>`,
+	}, {
+		Name:     "BackRef_Index",
+		Chain:    []dialect.Token{base.Common.BackRef("12")},
+		Expected: `(?:\12)`,
+	}, {
+		Name: "BackRef_FollowedByDigit",
+		Chain: []dialect.Token{
+			base.Group.Define(base.Chars.Digits()),
+			base.Common.BackRef("1"),
+			base.Common.Text("0"),
+		},
+		Expected: `(\d)(?:\1)0`,
+	}}.Run(t)
+}
+
+func TestRexCommonOneOf(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "Sorted",
+		Chain:    []dialect.Token{base.Common.OneOf("a", "a.b", "ab", "a")},
+		Expected: `a\.b|ab|a`,
+	}, {
+		Name:     "Single",
+		Chain:    []dialect.Token{base.Common.OneOf("a+", "a+")},
+		Expected: `a\+`,
+	}, {
+		Name:     "Concatenation",
+		Chain:    []dialect.Token{base.Chars.Begin(), base.Common.OneOf("go", "golang"), base.Chars.End()},
+		Expected: `^(?:golang|go)$`,
+	}, {
+		Name:     "Reader",
+		Chain:    []dialect.Token{base.Common.OneOfFromReader(strings.NewReader("b\r\n\na|b\nccc\n"))},
+		Expected: `a\|b|ccc|b`,
+	}}.Run(t)
+}
+
+// nolint: funlen // Unit test.
+func TestRexCommonRepeat(t *testing.T) {
+	oneOrMore := func(token dialect.Token) dialect.Token {
+		return base.Common.Repeat(token).OneOrMore()
+	}
+
+	test.RexTestCasesSlice{{
+		Name:     "TextSingle",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Text("a"))},
+		Expected: `a+`,
+	}, {
+		Name:     "TextEscaped",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Text("."))},
+		Expected: `\.+`,
+	}, {
+		Name:     "Text",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Text("ab"))},
+		Expected: `(?:ab)+`,
+	}, {
+		Name:     "Class",
+		Chain:    []dialect.Token{oneOrMore(base.Chars.Digits())},
+		Expected: `\d+`,
+	}, {
+		Name:     "Group",
+		Chain:    []dialect.Token{oneOrMore(base.Group.Define(base.Common.Text("ab")))},
+		Expected: `(ab)+`,
+	}, {
+		Name:     "Composite",
+		Chain:    []dialect.Token{oneOrMore(base.Group.Composite(base.Common.Text("a"), base.Common.Text("b")))},
+		Expected: `(a|b)+`,
+	}, {
+		Name:     "Anchor",
+		Chain:    []dialect.Token{oneOrMore(base.Chars.ASCIIWordBoundary())},
+		Expected: `\b+`,
+	}, {
+		Name:     "Repetition",
+		Chain:    []dialect.Token{oneOrMore(base.Chars.Digits().Repeat().Exactly(2))},
+		Expected: `(?:\d{2})+`,
+	}, {
+		Name:     "Helper",
+		Chain:    []dialect.Token{oneOrMore(base.Helper.MD5Hex())},
+		Expected: `(?:[[:xdigit:]]{32})+`,
+	}, {
+		Name:     "RawAtom",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`\p{Greek}`))},
+		Expected: `\p{Greek}+`,
+	}, {
+		Name:     "RawClass",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`[]a(|[:alpha:]]`))},
+		Expected: `[]a(|[:alpha:]]+`,
+	}, {
+		Name:     "RawGroup",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`(a|b)`))},
+		Expected: `(a|b)+`,
+	}, {
+		Name:     "RawEscape",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`\x{1F600}`))},
+		Expected: `\x{1F600}+`,
+	}, {
+		Name:     "RawQuoted",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`\Qa\E`))},
+		Expected: `\Qa\E+`,
+	}, {
+		Name:     "RawQuotedText",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`\Qab\E`))},
+		Expected: `(?:\Qab\E)+`,
+	}, {
+		Name:     "RawConcat",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`(a)b`))},
+		Expected: `(?:(a)b)+`,
+	}, {
+		Name:     "RawAlternation",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`a|b`))},
+		Expected: `(?:a|b)+`,
+	}, {
+		Name:     "RawRepetition",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`a{2,3}?`))},
+		Expected: `(?:a{2,3}?)+`,
+	}, {
+		Name:     "RawBrace",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`a{`))},
+		Expected: `(?:a{)+`,
+	}, {
+		Name:     "RawFlagsGroup",
+		Chain:    []dialect.Token{oneOrMore(base.Common.Raw(`(?i:ab)`))},
+		Expected: `(?i:ab)+`,
+	}}.Run(t)
+}
+
+func TestRexCommon_errors(t *testing.T) {
+	errRead := errors.New("read")
+
+	test.RexErrTestCasesSlice{{
+		Name:  "OneOfFromReader",
+		Chain: []dialect.Token{base.Common.OneOfFromReader(iotest.ErrReader(errRead))},
+		Err:   errRead,
+	}, {
+		Name:  "OneOf_Empty",
+		Chain: []dialect.Token{base.Common.OneOf()},
+		Err:   base.ErrNoValues,
+	}, {
+		Name:  "OneOfFromReader_Empty",
+		Chain: []dialect.Token{base.Common.OneOfFromReader(strings.NewReader("\n\r\n"))},
+		Err:   base.ErrNoValues,
+	}, {
+		Name:  "Repeat",
+		Chain: []dialect.Token{base.Common.Repeat(base.Chars.Range('z', 'a')).OneOrMore()},
+		Err:   base.ErrInvalidRange,
+	}, {
+		Name:  "Repeat_TextEmpty",
+		Chain: []dialect.Token{base.Common.Repeat(base.Common.Text("")).OneOrMore()},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Repeat_Flags",
+		Chain: []dialect.Token{base.Common.Repeat(base.Flags.CaseInsensitive()).OneOrMore()},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Repeat_RawFlags",
+		Chain: []dialect.Token{base.Common.Repeat(base.Common.Raw(`(?i)`)).OneOrMore()},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Repeat_Empty",
+		Chain: []dialect.Token{base.Common.Repeat(base.Group.Composite()).OneOrMore()},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "BackRef_Empty",
+		Chain: []dialect.Token{base.Common.BackRef("")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name:  "BackRef_Invalid",
+		Chain: []dialect.Token{base.Common.BackRef("a.b")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name:  "BackRef_Zero",
+		Chain: []dialect.Token{base.Common.BackRef("0")},
+		Err:   base.ErrInvalidGroupName,
 	}}.Run(t)
 }
@@ -25,3 +25,14 @@ func (ct CompositToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 
 	return helper.ProcessTokens(w, tokens)
 }
+
+// AST implements dialect.Node interface.
+func (ct CompositToken) AST() *dialect.AST {
+	sub := make([]*dialect.AST, 0, len(ct.tokens))
+
+	for _, tok := range ct.tokens {
+		sub = append(sub, dialect.ASTOf(tok))
+	}
+
+	return dialect.NewAST(dialect.KindAlternate, sub...)
+}
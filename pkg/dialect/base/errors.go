@@ -0,0 +1,21 @@
+package base
+
+import "errors"
+
+var (
+	// ErrInvalidRepetition is returned if a repetition has negative
+	// or reversed bounds.
+	ErrInvalidRepetition = errors.New("invalid repetition")
+	// ErrInvalidRange is returned if a range of characters is reversed.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrInvalidGroupName is returned if a name of a group is not valid.
+	ErrInvalidGroupName = errors.New("invalid group name")
+	// ErrUnknownUnicodeTable is returned if a unicode table is not known.
+	ErrUnknownUnicodeTable = errors.New("unknown unicode table")
+	// ErrInvalidClass is returned if runes of a class can't be computed,
+	// for example, if Common.Raw is used in class operations.
+	ErrInvalidClass = errors.New("invalid class")
+	// ErrNoValues is returned if a token requires values, but there
+	// are none, for example, Common.OneOf without arguments.
+	ErrNoValues = errors.New("no values")
+)
@@ -0,0 +1,160 @@
+package base
+
+import (
+	"strings"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// FlagsBaseDialect is a namespace that contains regular expression flags.
+//
+// Use the alias `rex.Flags`.
+type FlagsBaseDialect dialect.Dialect
+
+// Flags contains regular expression flags.
+const Flags FlagsBaseDialect = "FlagsBaseDialect"
+
+type flagSet uint8
+
+const (
+	flagCaseInsensitive flagSet = 1 << iota
+	flagMultiline
+	flagAnyIncludeNewLine
+	flagUngreedy
+)
+
+// flagLetters keeps the order in which flags are written.
+var flagLetters = []struct {
+	flag   flagSet
+	letter byte
+}{
+	{flag: flagCaseInsensitive, letter: 'i'},
+	{flag: flagMultiline, letter: 'm'},
+	{flag: flagAnyIncludeNewLine, letter: 's'},
+	{flag: flagUngreedy, letter: 'U'},
+}
+
+func (fs flagSet) String() string {
+	var sb strings.Builder
+
+	for _, fl := range flagLetters {
+		if fs&fl.flag != 0 {
+			_ = sb.WriteByte(fl.letter)
+		}
+	}
+
+	return sb.String()
+}
+
+// CaseInsensitive makes matching case-insensitive.
+//
+// Regex: `(?i)`.
+func (FlagsBaseDialect) CaseInsensitive() FlagToken {
+	return FlagToken{enabled: flagCaseInsensitive, disabled: 0}
+}
+
+// Multiline makes Chars.Begin and Chars.End match the begin and the end
+// of a line in addition to the begin and the end of the text.
+//
+// Regex: `(?m)`.
+func (FlagsBaseDialect) Multiline() FlagToken {
+	return FlagToken{enabled: flagMultiline, disabled: 0}
+}
+
+// AnyIncludeNewLine lets Chars.Any match a new line.
+//
+// Regex: `(?s)`.
+func (FlagsBaseDialect) AnyIncludeNewLine() FlagToken {
+	return FlagToken{enabled: flagAnyIncludeNewLine, disabled: 0}
+}
+
+// Ungreedy swaps meaning of repetitions: `x*` and `x*?`, `x+` and `x+?`, etc.
+//
+// Regex: `(?U)`.
+func (FlagsBaseDialect) Ungreedy() FlagToken {
+	return FlagToken{enabled: flagUngreedy, disabled: 0}
+}
+
+// Combine merges multiple flag tokens into one. If the same flag is
+// both set and cleared, then the last token wins.
+//
+// Example usage:
+//
+//	Flags.Combine(
+//	  Flags.CaseInsensitive(),
+//	  Flags.Multiline().Disable(),
+//	) // (?i-m)
+func (FlagsBaseDialect) Combine(flags ...FlagToken) FlagToken {
+	var combined FlagToken
+
+	for _, ft := range flags {
+		combined.enabled = (combined.enabled &^ ft.disabled) | ft.enabled
+		combined.disabled = (combined.disabled &^ ft.enabled) | ft.disabled
+	}
+
+	return combined
+}
+
+// FlagToken sets or clears flags for the rest of the current group.
+type FlagToken struct {
+	enabled  flagSet
+	disabled flagSet
+}
+
+// Disable inverts the token: enabled flags become cleared and
+// cleared flags become enabled.
+//
+// Example usage:
+//
+//	Flags.CaseInsensitive().Disable() // (?-i)
+func (ft FlagToken) Disable() FlagToken {
+	ft.enabled, ft.disabled = ft.disabled, ft.enabled
+
+	return ft
+}
+
+// Group applies flags only to the given tokens. The group is non-captured.
+//
+// It is overridden by GroupToken.WithName.
+//
+// Example usage:
+//
+//	Flags.CaseInsensitive().Group(Common.Text("abc")) // (?i:abc)
+func (ft FlagToken) Group(tokens ...dialect.Token) GroupToken {
+	gt := Group.NonCaptured(tokens...)
+	gt.flags = ft
+
+	return gt
+}
+
+// String returns flags without brackets: `i-m`.
+func (ft FlagToken) String() string {
+	val := ft.enabled.String()
+
+	if ft.disabled != 0 {
+		val += "-" + ft.disabled.String()
+	}
+
+	return val
+}
+
+// WriteTo implements dialect.Token interface.
+func (ft FlagToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	if ft.enabled == 0 && ft.disabled == 0 {
+		return 0, nil
+	}
+
+	return w.WriteString("(?" + ft.String() + ")")
+}
+
+// AST implements dialect.Node interface.
+func (ft FlagToken) AST() *dialect.AST {
+	if ft.enabled == 0 && ft.disabled == 0 {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	node := dialect.NewAST(dialect.KindFlags)
+	node.Flags = ft.String()
+
+	return node
+}
@@ -0,0 +1,171 @@
+package base_test
+
+import (
+	"testing"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// nolint: funlen // Unit test.
+func TestRexFlags(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "CaseInsensitive",
+		Chain:    []dialect.Token{base.Flags.CaseInsensitive()},
+		Expected: `(?i)`,
+	}, {
+		Name:     "Multiline",
+		Chain:    []dialect.Token{base.Flags.Multiline()},
+		Expected: `(?m)`,
+	}, {
+		Name:     "AnyIncludeNewLine",
+		Chain:    []dialect.Token{base.Flags.AnyIncludeNewLine()},
+		Expected: `(?s)`,
+	}, {
+		Name:     "Ungreedy",
+		Chain:    []dialect.Token{base.Flags.Ungreedy()},
+		Expected: `(?U)`,
+	}, {
+		Name:     "Disable",
+		Chain:    []dialect.Token{base.Flags.CaseInsensitive().Disable()},
+		Expected: `(?-i)`,
+	}, {
+		Name: "Combine",
+		Chain: []dialect.Token{base.Flags.Combine(
+			base.Flags.Ungreedy(),
+			base.Flags.CaseInsensitive(),
+			base.Flags.Multiline().Disable(),
+			base.Flags.AnyIncludeNewLine().Disable(),
+		)},
+		Expected: `(?iU-ms)`,
+	}, {
+		Name: "CombineLastWins",
+		Chain: []dialect.Token{base.Flags.Combine(
+			base.Flags.CaseInsensitive(),
+			base.Flags.CaseInsensitive().Disable(),
+		)},
+		Expected: `(?-i)`,
+	}, {
+		Name: "CombineDisable",
+		Chain: []dialect.Token{base.Flags.Combine(
+			base.Flags.CaseInsensitive(),
+			base.Flags.Multiline().Disable(),
+		).Disable()},
+		Expected: `(?m-i)`,
+	}, {
+		Name:     "CombineEmpty",
+		Chain:    []dialect.Token{base.Flags.Combine()},
+		Expected: ``,
+	}, {
+		Name: "Group",
+		Chain: []dialect.Token{
+			base.Flags.CaseInsensitive().Group(base.Common.Text("a")),
+			base.Common.Text("b"),
+		},
+		Expected: `(?i:a)b`,
+	}, {
+		Name: "GroupDisable",
+		Chain: []dialect.Token{
+			base.Flags.CaseInsensitive(),
+			base.Flags.CaseInsensitive().Disable().Group(base.Common.Text("a")),
+		},
+		Expected: `(?i)(?-i:a)`,
+	}, {
+		Name: "GroupRepeat",
+		Chain: []dialect.Token{
+			base.Flags.Multiline().Group(base.Chars.Begin()).Repeat().ZeroOrOne(),
+		},
+		Expected: `(?m:^)?`,
+	}, {
+		Name: "GroupEmptyFlags",
+		Chain: []dialect.Token{
+			base.Flags.Combine().Group(base.Common.Text("a")),
+		},
+		Expected: `(?:a)`,
+	}}.Run(t)
+}
+
+func TestRexFlagsMatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Tokens   []dialect.Token
+		Value    string
+		Expected bool
+	}{{
+		Name: "case_insensitive",
+		Tokens: []dialect.Token{
+			base.Flags.CaseInsensitive(),
+			base.Common.Text("rex"),
+		},
+		Value:    "REX",
+		Expected: true,
+	}, {
+		Name: "case_insensitive_scoped",
+		Tokens: []dialect.Token{
+			base.Flags.CaseInsensitive().Group(base.Common.Text("r")),
+			base.Common.Text("ex"),
+		},
+		Value:    "REX",
+		Expected: false,
+	}, {
+		Name: "multiline",
+		Tokens: []dialect.Token{
+			base.Flags.Multiline(),
+			base.Common.Text("b"),
+			base.Chars.End(),
+		},
+		Value:    "b\nc",
+		Expected: true,
+	}, {
+		Name: "any_include_new_line",
+		Tokens: []dialect.Token{
+			base.Flags.AnyIncludeNewLine(),
+			base.Chars.Begin(),
+			base.Chars.Any().Repeat().OneOrMore(),
+			base.Chars.End(),
+		},
+		Value:    "a\nb",
+		Expected: true,
+	}, {
+		Name: "any_exclude_new_line",
+		Tokens: []dialect.Token{
+			base.Chars.Begin(),
+			base.Chars.Any().Repeat().OneOrMore(),
+			base.Chars.End(),
+		},
+		Value:    "a\nb",
+		Expected: false,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := rex.New(tc.Tokens...).MustCompile().MatchString(tc.Value)
+			if actual != tc.Expected {
+				t.Fatalf("Actual: %v, Expected: %v", actual, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestRexFlagsUngreedy(t *testing.T) {
+	t.Parallel()
+
+	re := rex.New(
+		base.Flags.Ungreedy(),
+		base.Chars.Any().Repeat().OneOrMore(),
+	).MustCompile()
+
+	const expected = "a"
+
+	if actual := re.FindString("abc"); actual != expected {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+}
@@ -1,7 +1,7 @@
 package base
 
 import (
-	"regexp"
+	"fmt"
 
 	"github.com/hedhyw/rex/internal/helper"
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -12,14 +12,23 @@ import (
 // Use the alias `rex.Group`.
 type GroupBaseDialect dialect.Dialect
 
-// Define a group with ranges of expressions.
+// Define a group with ranges of expressions. Tokens with alternations
+// are grouped, if there are several tokens: `((?:a|b)c)`.
 func (GroupBaseDialect) Define(tokens ...dialect.Token) GroupToken {
 	return GroupToken{
-		prefix: "",
-		tokens: tokens,
+		kind:   groupCaptured,
+		name:   "",
+		flags:  Flags.Combine(),
+		tokens: helper.GroupAlternations(tokens, nonCaptured),
+		err:    nil,
 	}
 }
 
+// nonCaptured wraps the token in a non-captured group.
+func nonCaptured(token dialect.Token) dialect.Token {
+	return Group.NonCaptured(token)
+}
+
 // NonCaptured defines a group as a non-captured. It is a synonym to
 // Group.Define(...).NonCaptured().
 func (g GroupBaseDialect) NonCaptured(tokens ...dialect.Token) GroupToken {
@@ -33,47 +42,184 @@ func (GroupBaseDialect) Composite(tokens ...dialect.Token) GroupToken {
 		return Group.Define(tokens...)
 	}
 
-	return GroupToken{
-		prefix: "",
-		tokens: []dialect.Token{
-			CompositToken{tokens: tokens},
-		},
-	}
+	return Group.Define(CompositToken{tokens: tokens})
+}
+
+// LookAhead matches if tokens match next, but doesn't consume characters.
+// It is not supported by RE2, see RegExp.StringFor.
+//
+// Regex: `(?=...)`.
+func (g GroupBaseDialect) LookAhead(tokens ...dialect.Token) GroupToken {
+	return g.withKind(groupLookAhead, tokens)
+}
+
+// NegativeLookAhead matches if tokens don't match next, but doesn't
+// consume characters. It is not supported by RE2, see RegExp.StringFor.
+//
+// Regex: `(?!...)`.
+func (g GroupBaseDialect) NegativeLookAhead(tokens ...dialect.Token) GroupToken {
+	return g.withKind(groupNegativeLookAhead, tokens)
+}
+
+// LookBehind matches if tokens match before the current position.
+// It is not supported by RE2, see RegExp.StringFor.
+//
+// Regex: `(?<=...)`.
+func (g GroupBaseDialect) LookBehind(tokens ...dialect.Token) GroupToken {
+	return g.withKind(groupLookBehind, tokens)
+}
+
+// NegativeLookBehind matches if tokens don't match before the current
+// position. It is not supported by RE2, see RegExp.StringFor.
+//
+// Regex: `(?<!...)`.
+func (g GroupBaseDialect) NegativeLookBehind(tokens ...dialect.Token) GroupToken {
+	return g.withKind(groupNegativeLookBehind, tokens)
+}
+
+// Atomic matches tokens, but doesn't backtrack into them after they
+// matched. It is not supported by RE2, see RegExp.StringFor.
+//
+// Regex: `(?>...)`.
+func (g GroupBaseDialect) Atomic(tokens ...dialect.Token) GroupToken {
+	return g.withKind(groupAtomic, tokens)
+}
+
+func (g GroupBaseDialect) withKind(kind groupKind, tokens []dialect.Token) GroupToken {
+	gt := g.Define(tokens...)
+	gt.kind = kind
+
+	return gt
 }
 
 // Group helps to define groups.
 const Group GroupBaseDialect = "GroupBaseDialect"
 
+type groupKind uint8
+
+const (
+	groupCaptured groupKind = iota
+	groupNonCaptured
+	groupLookAhead
+	groupNegativeLookAhead
+	groupLookBehind
+	groupNegativeLookBehind
+	groupAtomic
+)
+
+// groupPrefixes are written after the opening bracket.
+var groupPrefixes = map[groupKind]string{
+	groupLookAhead:          "?=",
+	groupNegativeLookAhead:  "?!",
+	groupLookBehind:         "?<=",
+	groupNegativeLookBehind: "?<!",
+	groupAtomic:             "?>",
+}
+
 // GroupToken defines a token that wraps a range of tokens with a `(...)`.
 type GroupToken struct {
-	prefix string
+	kind   groupKind
+	name   string
+	flags  FlagToken
 	tokens []dialect.Token
+	err    error
+}
+
+// prefix returns a text after the opening bracket.
+func (gt GroupToken) prefix() string {
+	switch {
+	case gt.kind == groupNonCaptured:
+		return "?" + gt.flags.String() + ":"
+	case gt.kind == groupCaptured && gt.name != "":
+		return "?P<" + gt.name + ">"
+	default:
+		return groupPrefixes[gt.kind]
+	}
 }
 
 // WriteTo implements dialect.Token interface.
 func (gt GroupToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 	if len(gt.tokens) == 0 {
-		return 0, nil
+		return 0, gt.err
 	}
 
-	tokens := make([]dialect.Token, 0, 3+len(gt.tokens))
+	tokens := make([]dialect.Token, 0, 5+len(gt.tokens))
+
+	if namer, ok := w.(dialect.GroupNamer); ok && gt.kind == groupCaptured && gt.name != "" && gt.err == nil {
+		gt.name, gt.err = namer.GroupName(gt.name)
+	}
 
 	tokens = append(tokens, helper.ByteToken('('))
-	if gt.prefix != "" {
-		tokens = append(tokens, helper.StringToken(gt.prefix))
+	if prefix := gt.prefix(); prefix != "" {
+		tokens = append(tokens, helper.StringToken(prefix))
 	}
 
 	tokens = append(tokens, gt.tokens...)
 	tokens = append(tokens, helper.ByteToken(')'))
 
+	if gt.err != nil {
+		tokens = append(tokens, helper.ErrorToken(gt.err))
+	}
+
 	return helper.ProcessTokens(w, tokens)
 }
 
-// WithName add a name to captured group.
+// AST implements dialect.Node interface.
+func (gt GroupToken) AST() *dialect.AST {
+	if len(gt.tokens) == 0 {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	sub := dialect.ConcatAST(gt.tokens...)
+
+	switch gt.kind {
+	case groupLookAhead, groupNegativeLookAhead:
+		node := dialect.NewAST(dialect.KindLookAhead, sub)
+		node.Negated = gt.kind == groupNegativeLookAhead
+
+		return node
+	case groupLookBehind, groupNegativeLookBehind:
+		node := dialect.NewAST(dialect.KindLookBehind, sub)
+		node.Negated = gt.kind == groupNegativeLookBehind
+
+		return node
+	case groupAtomic:
+		return dialect.NewAST(dialect.KindAtomic, sub)
+	case groupNonCaptured:
+		node := dialect.NewAST(dialect.KindGroup, sub)
+		node.Flags = gt.flags.String()
+
+		return node
+	default:
+		node := dialect.NewAST(dialect.KindGroup, sub)
+		node.Capture = true
+		node.Name = gt.name
+
+		return node
+	}
+}
+
+// WithName add a name to captured group. The name should not be
+// empty and should contain only word characters `[0-9A-Za-z_]`,
+// otherwise ErrInvalidGroupName is reported. Names should be unique
+// within the regular expression.
 //
 // It overrides non-captured if set.
 func (gt GroupToken) WithName(name string) GroupToken {
-	gt.prefix = "?P<" + regexp.QuoteMeta(name) + ">"
+	switch {
+	case name == "":
+		gt.err = fmt.Errorf("%w: Group.WithName: name is empty", ErrInvalidGroupName)
+	case !helper.IsWord(name):
+		gt.err = fmt.Errorf(
+			"%w: Group.WithName(%q): only word characters are allowed",
+			ErrInvalidGroupName, name,
+		)
+	default:
+		gt.err = nil
+	}
+
+	gt.kind = groupCaptured
+	gt.name = name
 
 	return gt
 }
@@ -84,7 +230,9 @@ func (gt GroupToken) WithName(name string) GroupToken {
 //
 // It overrides name if set.
 func (gt GroupToken) NonCaptured() GroupToken {
-	gt.prefix = "?:"
+	gt.kind = groupNonCaptured
+	gt.name = ""
+	gt.err = nil
 
 	return gt
 }
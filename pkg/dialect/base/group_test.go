@@ -70,5 +70,62 @@ func TestRexGroup(t *testing.T) {
 			).NonCaptured().Repeat().ZeroOrMore(),
 		},
 		Expected: `(?:a)*`,
+	}, {
+		Name: "LookAhead",
+		Chain: []dialect.Token{
+			base.Group.LookAhead(base.Chars.Single('a')),
+			base.Group.NegativeLookAhead(base.Chars.Single('b')),
+		},
+		Expected: `(?=a)(?!b)`,
+	}, {
+		Name: "LookBehind",
+		Chain: []dialect.Token{
+			base.Group.LookBehind(base.Chars.Single('a')),
+			base.Group.NegativeLookBehind(base.Chars.Single('b')),
+		},
+		Expected: `(?<=a)(?<!b)`,
+	}, {
+		Name: "Atomic",
+		Chain: []dialect.Token{
+			base.Group.Atomic(base.Chars.Single('a')).Repeat().OneOrMore(),
+		},
+		Expected: `(?>a)+`,
+	}, {
+		Name: "LookAheadWithName",
+		Chain: []dialect.Token{
+			base.Group.LookAhead(base.Chars.Single('a')).WithName("name"),
+		},
+		Expected: `(?P<name>a)`,
+	}}.Run(t)
+}
+
+func TestRexGroup_errors(t *testing.T) {
+	test.RexErrTestCasesSlice{{
+		Name: "EmptyName",
+		Chain: []dialect.Token{
+			base.Group.Define(base.Chars.Single('a')).WithName(""),
+		},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "EmptyNameEmptyGroup",
+		Chain: []dialect.Token{
+			base.Group.Define().WithName(""),
+		},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "NotWordName",
+		Chain: []dialect.Token{
+			base.Group.Define(base.Chars.Single('a')).WithName("a.b"),
+		},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "DuplicateName",
+		Chain: []dialect.Token{
+			base.Group.Define(base.Chars.Single('a')).WithName("name"),
+			base.Group.Define(
+				base.Group.Define(base.Chars.Single('b')).WithName("name"),
+			),
+		},
+		Err: base.ErrInvalidGroupName,
 	}}.Run(t)
 }
@@ -0,0 +1,264 @@
+package base
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/internal/helper"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// WordsToken matches one of words. Common prefixes and suffixes of words
+// are factored, so the pattern is short and it is matched fast.
+type WordsToken struct {
+	words           []string
+	caseInsensitive bool
+	wholeWords      bool
+
+	token dialect.Token
+}
+
+// OneOfWords matches one of words. The alternation is factored by common
+// prefixes and suffixes of words. Longer words are preferred. Duplicates
+// are ignored.
+//
+// Example usage:
+//
+//	Group.OneOfWords("foobar", "foobaz", "fooqux") // foo(?:ba[rz]|qux)
+//	Group.OneOfWords("bat", "cat")                 // [bc]at
+//	Group.OneOfWords("go", "golang")               // go(?:lang)?
+func (GroupBaseDialect) OneOfWords(words ...string) WordsToken {
+	return WordsToken{
+		words:           words,
+		caseInsensitive: false,
+		wholeWords:      false,
+
+		token: nil,
+	}.build()
+}
+
+// CaseInsensitive matches words in any case.
+//
+// Regex: `(?i:...)`.
+func (wt WordsToken) CaseInsensitive() WordsToken {
+	wt.caseInsensitive = true
+
+	return wt.build()
+}
+
+// WholeWords matches only whole words, that are surrounded by ASCII
+// word boundaries.
+//
+// Regex: `\b...\b`.
+func (wt WordsToken) WholeWords() WordsToken {
+	wt.wholeWords = true
+
+	return wt.build()
+}
+
+// Repeat the token.
+func (wt WordsToken) Repeat() Repetition {
+	return Common.Repeat(wt)
+}
+
+// WriteTo implements dialect.Token interface.
+func (wt WordsToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	if wt.token == nil {
+		return 0, nil
+	}
+
+	return wt.token.WriteTo(w)
+}
+
+// AST implements dialect.Node interface.
+func (wt WordsToken) AST() *dialect.AST {
+	if wt.token == nil {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	return dialect.ASTOf(wt.token)
+}
+
+func (wt WordsToken) build() WordsToken {
+	root := newWordsTrie()
+
+	for _, word := range wt.words {
+		if wt.caseInsensitive {
+			word = strings.ToLower(word)
+		}
+
+		root.insert(word)
+	}
+
+	token := root.token()
+
+	switch {
+	case token == nil && !root.end:
+		wt.token = nil
+
+		return wt
+	case token == nil:
+		// Only an empty word.
+		token = newConcatToken()
+	case root.end:
+		token = Common.Repeat(token).ZeroOrOne()
+	}
+
+	if wt.caseInsensitive {
+		token = Flags.CaseInsensitive().Group(token)
+	}
+
+	if wt.wholeWords {
+		token = newConcatToken(Chars.ASCIIWordBoundary(), token, Chars.ASCIIWordBoundary())
+	}
+
+	wt.token = token
+
+	return wt
+}
+
+// wordsTrie is a prefix tree of words.
+type wordsTrie struct {
+	children map[rune]*wordsTrie
+	// end is set if a word ends at this node.
+	end bool
+}
+
+func newWordsTrie() *wordsTrie {
+	return &wordsTrie{
+		children: make(map[rune]*wordsTrie),
+		end:      false,
+	}
+}
+
+func (t *wordsTrie) insert(word string) {
+	node := t
+
+	for _, r := range word {
+		child, ok := node.children[r]
+		if !ok {
+			child = newWordsTrie()
+			node.children[r] = child
+		}
+
+		node = child
+	}
+
+	node.end = true
+}
+
+// token returns the pattern of words, that follow the node. It returns
+// nil if there are no such words.
+func (t *wordsTrie) token() dialect.Token {
+	if len(t.children) == 0 {
+		return nil
+	}
+
+	runes := make([]rune, 0, len(t.children))
+	for r := range t.children {
+		runes = append(runes, r)
+	}
+
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	// Branches with the same tail are merged into a class: `[bc]at`.
+	type branch struct {
+		ranges []rune
+		tail   dialect.Token
+	}
+
+	var (
+		branches []*branch
+		byTail   = make(map[string]*branch, len(runes))
+	)
+
+	for _, r := range runes {
+		tail := t.children[r].suffix()
+		key := tokenString(tail)
+
+		if b, ok := byTail[key]; ok {
+			b.ranges = append(b.ranges, r, r)
+
+			continue
+		}
+
+		b := &branch{ranges: []rune{r, r}, tail: tail}
+		byTail[key] = b
+		branches = append(branches, b)
+	}
+
+	alternatives := make([]dialect.Token, 0, len(branches))
+
+	for _, b := range branches {
+		alternatives = append(alternatives, newConcatToken(runesToken(b.ranges), b.tail))
+	}
+
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+
+	return CompositToken{tokens: alternatives}
+}
+
+// suffix returns the pattern of the node including optional words,
+// that end at the node. It returns nil if there are no such words.
+func (t *wordsTrie) suffix() dialect.Token {
+	token := t.token()
+
+	switch {
+	case token == nil:
+		return nil
+	case t.end:
+		return Common.Repeat(token).ZeroOrOne()
+	default:
+		return token
+	}
+}
+
+// runesToken returns a single character or a class of ranges.
+func runesToken(ranges []rune) dialect.Token {
+	if len(ranges) == 2 && ranges[0] == ranges[1] {
+		return Chars.Single(ranges[0])
+	}
+
+	ranges = charclass.Normalize(ranges)
+
+	return newClassToken(newRangeClassPart(formatClassRanges(ranges), "", ranges))
+}
+
+func tokenString(token dialect.Token) string {
+	var sb strings.Builder
+
+	if token != nil {
+		_, _ = token.WriteTo(&sb)
+	}
+
+	return sb.String()
+}
+
+// concatToken matches tokens one after another.
+type concatToken []dialect.Token
+
+// newConcatToken creates a concatenation of tokens. Nil tokens are skipped.
+func newConcatToken(tokens ...dialect.Token) concatToken {
+	nonNil := make([]dialect.Token, 0, len(tokens))
+
+	for _, tok := range tokens {
+		if tok != nil {
+			nonNil = append(nonNil, tok)
+		}
+	}
+
+	return helper.GroupAlternations(nonNil, nonCaptured)
+}
+
+// WriteTo implements dialect.Token interface.
+func (ct concatToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	return helper.ProcessTokens(w, ct)
+}
+
+// AST implements dialect.Node interface.
+func (ct concatToken) AST() *dialect.AST {
+	return dialect.ConcatAST(ct...)
+}
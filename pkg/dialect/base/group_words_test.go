@@ -0,0 +1,116 @@
+package base_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// nolint: funlen // Unit test.
+func TestGroupOneOfWords(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "CommonPrefix",
+		Chain:    []dialect.Token{base.Group.OneOfWords("foobar", "foobaz", "fooqux")},
+		Expected: `foo(?:ba[rz]|qux)`,
+	}, {
+		Name:     "CommonSuffix",
+		Chain:    []dialect.Token{base.Group.OneOfWords("cats", "bats", "dogs", "rats")},
+		Expected: `[bcr]ats|dogs`,
+	}, {
+		Name:     "Prefix",
+		Chain:    []dialect.Token{base.Group.OneOfWords("go", "golang")},
+		Expected: `go(?:lang)?`,
+	}, {
+		Name:     "PrefixSingle",
+		Chain:    []dialect.Token{base.Group.OneOfWords("a", "ab")},
+		Expected: `ab?`,
+	}, {
+		Name:     "Characters",
+		Chain:    []dialect.Token{base.Group.OneOfWords("a", "b", "c", "x")},
+		Expected: `[a-cx]`,
+	}, {
+		Name:     "Duplicates",
+		Chain:    []dialect.Token{base.Group.OneOfWords("abc", "abc")},
+		Expected: `abc`,
+	}, {
+		Name:     "Escaped",
+		Chain:    []dialect.Token{base.Group.OneOfWords("a.b", "a+b", "a-b")},
+		Expected: `a[\+\x2D\.]b`,
+	}, {
+		Name:     "EmptyWord",
+		Chain:    []dialect.Token{base.Group.OneOfWords("", "ab")},
+		Expected: `(?:ab)?`,
+	}, {
+		Name:     "OnlyEmptyWord",
+		Chain:    []dialect.Token{base.Group.OneOfWords("")},
+		Expected: ``,
+	}, {
+		Name:     "NoWords",
+		Chain:    []dialect.Token{base.Group.OneOfWords()},
+		Expected: ``,
+	}, {
+		Name:     "CaseInsensitive",
+		Chain:    []dialect.Token{base.Group.OneOfWords("Select", "SELECT", "from").CaseInsensitive()},
+		Expected: `(?i:from|select)`,
+	}, {
+		Name:     "WholeWords",
+		Chain:    []dialect.Token{base.Group.OneOfWords("if", "else").WholeWords()},
+		Expected: `\b(?:else|if)\b`,
+	}, {
+		Name:     "Concatenation",
+		Chain:    []dialect.Token{base.Group.OneOfWords("ab", "cd"), base.Common.Text("x")},
+		Expected: `(?:ab|cd)x`,
+	}, {
+		Name:     "Repeat",
+		Chain:    []dialect.Token{base.Group.OneOfWords("ab", "cd").Repeat().OneOrMore()},
+		Expected: `(?:ab|cd)+`,
+	}}.Run(t)
+}
+
+func TestGroupOneOfWordsMatch(t *testing.T) {
+	test.MatchTestCaseGroupSlice{
+		test.MatchTestCaseSlice{
+			{Name: "foobar", Value: "foobar"},
+			{Name: "foobaz", Value: "foobaz"},
+			{Name: "fooqux", Value: "fooqux"},
+			{Name: "go", Value: "go"},
+			{Name: "golang", Value: "golang"},
+		}.WithMatched(true),
+		test.MatchTestCaseSlice{
+			{Name: "foo", Value: "foo"},
+			{Name: "fooba", Value: "fooba"},
+			{Name: "foobarz", Value: "foobarz"},
+			{Name: "gola", Value: "gola"},
+			{Name: "empty", Value: ""},
+		}.WithMatched(false),
+	}.Run(t, base.Group.OneOfWords("foobar", "foobaz", "fooqux", "go", "golang"))
+}
+
+func TestGroupOneOfWordsMany(t *testing.T) {
+	t.Parallel()
+
+	words := make([]string, 0, 5000)
+	for i := 0; i < cap(words); i++ {
+		words = append(words, fmt.Sprintf("route_%d_%x", i%97, i))
+	}
+
+	re := rex.New(
+		rex.Chars.Begin(),
+		rex.Group.OneOfWords(words...),
+		rex.Chars.End(),
+	).MustCompile()
+
+	for _, word := range words {
+		if !re.MatchString(word) {
+			t.Fatalf("%q is not matched", word)
+		}
+	}
+
+	if re.MatchString("route_1_") {
+		t.Fatal("prefix is matched")
+	}
+}
@@ -0,0 +1,12 @@
+package base
+
+// Keywords matches one of whole words, for example reserved identifiers
+// or stop words. It is a synonym to Group.OneOfWords(words...).WholeWords().
+//
+// Example usage:
+//
+//	Helper.Keywords("if", "else", "for")                   // \b(?:else|for|if)\b
+//	Helper.Keywords("select", "from").CaseInsensitive() // \b(?i:from|select)\b
+func (HelperDialect) Keywords(words ...string) WordsToken {
+	return Group.OneOfWords(words...).WholeWords()
+}
@@ -0,0 +1,36 @@
+package base_test
+
+import (
+	"testing"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+func TestHelperKeywords(t *testing.T) {
+	test.MatchTestCaseGroupSlice{
+		test.MatchTestCaseSlice{
+			{Name: "if", Value: "if"},
+			{Name: "else", Value: "else"},
+			{Name: "for", Value: "for"},
+		}.WithMatched(true),
+		test.MatchTestCaseSlice{
+			{Name: "upper", Value: "IF"},
+			{Name: "prefix", Value: "fo"},
+			{Name: "longer", Value: "format"},
+		}.WithMatched(false),
+	}.Run(t, base.Helper.Keywords("if", "else", "for"))
+}
+
+func TestHelperKeywordsCaseInsensitive(t *testing.T) {
+	test.MatchTestCaseGroupSlice{
+		test.MatchTestCaseSlice{
+			{Name: "lower", Value: "select"},
+			{Name: "upper", Value: "SELECT"},
+			{Name: "mixed", Value: "From"},
+		}.WithMatched(true),
+		test.MatchTestCaseSlice{
+			{Name: "other", Value: "where"},
+		}.WithMatched(false),
+	}.Run(t, base.Helper.Keywords("select", "from").CaseInsensitive())
+}
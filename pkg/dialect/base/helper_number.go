@@ -33,10 +33,16 @@ func (h HelperDialect) NumberRange(from int32, to int32) NumberRange {
 	}
 }
 
+// WriteTo implements dialect.Token interface.
 func (nr NumberRange) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 	return nr.processRange(nr.initialFrom, nr.initialTo).WriteTo(w)
 }
 
+// AST implements dialect.Node interface.
+func (nr NumberRange) AST() *dialect.AST {
+	return dialect.ASTOf(nr.processRange(nr.initialFrom, nr.initialTo))
+}
+
 func (nr NumberRange) processRange(from, to int64) dialect.Token {
 	if from > to {
 		to, from = from, to
@@ -19,6 +19,20 @@ func (rt RawToken) Unwrap() dialect.ClassToken {
 
 // WriteTo implements dialect.Token interface.
 func (rt RawToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	return w.WriteString(rt.String())
+}
+
+// AST implements dialect.Node interface. The raw expression is not parsed.
+func (rt RawToken) AST() *dialect.AST {
+	node := dialect.NewAST(dialect.KindRaw)
+	node.Value = rt.String()
+
+	return node
+}
+
+// String returns the raw regular expression. Comments are removed
+// for verbose tokens.
+func (rt RawToken) String() string {
 	value := rt.value
 
 	if rt.verbose {
@@ -33,7 +47,7 @@ func (rt RawToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 		value = strings.Join(lines, "")
 	}
 
-	return w.WriteString(value)
+	return value
 }
 
 // removeComment removes everythong after '#' if it is not escaped by
@@ -2,6 +2,7 @@ package base
 
 import (
 	"fmt"
+	"regexp/syntax"
 
 	"github.com/hedhyw/rex/internal/helper"
 	"github.com/hedhyw/rex/pkg/dialect"
@@ -11,13 +12,112 @@ import (
 type Repetition struct {
 	token  dialect.Token
 	suffix string
+	err    error
+
+	from        int
+	to          int
+	preferFewer bool
 }
 
+// unlimited is an upper bound of repetitions without limits.
+const unlimited = -1
+
+// maxRepetitionCount is the limit of RE2 for counts of repetitions,
+// including nested ones.
+const maxRepetitionCount = 1000
+
 func newRepetition(token dialect.Token) Repetition {
 	return Repetition{
 		token:  token,
 		suffix: "",
+		err:    nil,
+
+		from:        1,
+		to:          1,
+		preferFewer: false,
+	}
+}
+
+// Repeat the repeated token again. The token is wrapped in
+// a non-captured group: `(?:a+){2}`.
+func (r Repetition) Repeat() Repetition {
+	if r.token == nil {
+		return newRepetition(nil)
+	}
+
+	if r.suffix == "" && r.err == nil {
+		return newRepetition(r.token)
+	}
+
+	return newRepetition(Group.NonCaptured(r))
+}
+
+// repetitionCount returns the count of repetitions, that is checked
+// by RE2.
+func repetitionCount(from, to int) int {
+	switch {
+	case to == 0, to == unlimited && from == 0:
+		// It doesn't limit nested repetitions.
+		return 1
+	case to == unlimited:
+		return from
+	default:
+		return to
+	}
+}
+
+// nestedCount returns the largest product of counts of nested
+// repetitions of the tree. Raw expressions are parsed, invalid ones
+// are reported by the compiler.
+func nestedCount(node *dialect.AST) int {
+	if node.Kind == dialect.KindRaw {
+		re, err := syntax.Parse(node.Value, syntax.Perl)
+		if err != nil {
+			return 1
+		}
+
+		return nestedSyntaxCount(re)
+	}
+
+	count := 1
+
+	for _, sub := range node.Sub {
+		if subCount := nestedCount(sub); subCount > count {
+			count = subCount
+		}
+	}
+
+	if node.Kind == dialect.KindRepeat {
+		count *= repetitionCount(node.Min, node.Max)
 	}
+
+	return count
+}
+
+func nestedSyntaxCount(re *syntax.Regexp) int {
+	count := 1
+
+	for _, sub := range re.Sub {
+		if subCount := nestedSyntaxCount(sub); subCount > count {
+			count = subCount
+		}
+	}
+
+	if re.Op == syntax.OpRepeat {
+		count *= repetitionCount(re.Min, re.Max)
+	}
+
+	return count
+}
+
+// limit returns the largest count of the repetition, so the product
+// with nested counts doesn't exceed the limit of RE2.
+func (r Repetition) limit() int {
+	if r.token == nil {
+		return maxRepetitionCount
+	}
+
+	return maxRepetitionCount / nestedCount(dialect.ASTOf(r.token))
 }
 
 // WriteTo implements dialect.Token interface.
@@ -32,92 +132,208 @@ func (r Repetition) WriteTo(w dialect.StringByteWriter) (n int, err error) {
 		}
 	}
 
+	if r.err != nil {
+		tokens = append(tokens, helper.ErrorToken(r.err))
+	}
+
 	return helper.ProcessTokens(w, tokens)
 }
 
-func (r Repetition) withSuffix(suffix string) dialect.Token {
-	r.suffix = suffix
+// AST implements dialect.Node interface.
+func (r Repetition) AST() *dialect.AST {
+	if r.token == nil {
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+
+	if r.suffix == "" {
+		return dialect.ASTOf(r.token)
+	}
+
+	node := dialect.NewAST(dialect.KindRepeat, dialect.ASTOf(r.token))
+	node.Min = r.from
+	node.Max = r.to
+	node.PreferFewer = r.preferFewer
+
+	return node
+}
+
+// withBounds sets the shortest suffix for the bounds.
+func (r Repetition) withBounds(from, to int, preferFewer bool) Repetition {
+	if from == to {
+		// Both forms are equivalent.
+		preferFewer = false
+	}
+
+	r.suffix = repetitionSuffix(from, to, preferFewer)
+	r.from = from
+	r.to = to
+	r.preferFewer = preferFewer
 
 	return r
 }
 
+// repetitionSuffix returns the shortest suffix: `?` instead of `{0,1}`,
+// `*` instead of `{0,}`, `+` instead of `{1,}` and nothing instead
+// of `{1}`.
+func repetitionSuffix(from, to int, preferFewer bool) string {
+	var suffix string
+
+	switch {
+	case from == 1 && to == 1:
+		return ""
+	case from == to:
+		return fmt.Sprintf("{%d}", from)
+	case from == 0 && to == 1:
+		suffix = "?"
+	case from == 0 && to == unlimited:
+		suffix = "*"
+	case from == 1 && to == unlimited:
+		suffix = "+"
+	case to == unlimited:
+		suffix = fmt.Sprintf("{%d,}", from)
+	default:
+		suffix = fmt.Sprintf("{%d,%d}", from, to)
+	}
+
+	if preferFewer {
+		suffix += "?"
+	}
+
+	return suffix
+}
+
+func (r Repetition) withError(err error) Repetition {
+	r.err = err
+
+	return r
+}
+
+func (r Repetition) validateCount(method string, n int) error {
+	switch limit := r.limit(); {
+	case n < 0:
+		return fmt.Errorf("%w: %s(%d): negative count", ErrInvalidRepetition, method, n)
+	case n > limit:
+		return fmt.Errorf("%w: %s(%d): count is greater than %d", ErrInvalidRepetition, method, n, limit)
+	default:
+		return nil
+	}
+}
+
+func (r Repetition) validateBounds(method string, from, to int) error {
+	switch limit := r.limit(); {
+	case from < 0 || to < 0:
+		return fmt.Errorf("%w: %s(%d, %d): negative count", ErrInvalidRepetition, method, from, to)
+	case from > to:
+		return fmt.Errorf("%w: %s(%d, %d): from is greater than to", ErrInvalidRepetition, method, from, to)
+	case to > limit:
+		return fmt.Errorf("%w: %s(%d, %d): count is greater than %d", ErrInvalidRepetition, method, from, to, limit)
+	default:
+		return nil
+	}
+}
+
 // OneOrMore repeats one or more, prefer more chars.
 //
 // Regex: `+`.
 func (r Repetition) OneOrMore() dialect.Token {
-	return r.withSuffix("+")
+	return r.withBounds(1, unlimited, false)
 }
 
 // OneOrMorePreferFewer repeats one or more, prefer fewer chars.
 //
 // Regex: `+?`.
 func (r Repetition) OneOrMorePreferFewer() dialect.Token {
-	return r.withSuffix("+?")
+	return r.withBounds(1, unlimited, true)
 }
 
 // ZeroOrMore repeats zero or more, prefer more chars.
 //
 // Regex: `*`.
 func (r Repetition) ZeroOrMore() dialect.Token {
-	return r.withSuffix("*")
+	return r.withBounds(0, unlimited, false)
 }
 
 // ZeroOrMorePreferFewer repeats zero or more, prefer fewer chars.
 //
 // Regex: `*?`.
 func (r Repetition) ZeroOrMorePreferFewer() dialect.Token {
-	return r.withSuffix("*?")
+	return r.withBounds(0, unlimited, true)
 }
 
 // ZeroOrOne repeats zero or one x, prefer one.
 //
 // Regex: `?`.
 func (r Repetition) ZeroOrOne() dialect.Token {
-	return r.withSuffix("?")
+	return r.withBounds(0, 1, false)
 }
 
 // ZeroOrOnePreferZero repeats zero or one x, prefer zero.
 //
 // Regex: `??`.
 func (r Repetition) ZeroOrOnePreferZero() dialect.Token {
-	return r.withSuffix("??")
+	return r.withBounds(0, 1, true)
 }
 
-// Exactly n times. It doesn't validate an input.
+// Exactly n times. The count n should not be negative or greater
+// than 1000, otherwise ErrInvalidRepetition is reported.
 //
-// Regex: `{n}`.
+// Regex: `{n}`, or nothing if n is 1.
 func (r Repetition) Exactly(n int) dialect.Token {
-	return r.withSuffix(fmt.Sprintf("{%d}", n))
+	if err := r.validateCount("Exactly", n); err != nil {
+		return r.withError(err)
+	}
+
+	return r.withBounds(n, n, false)
 }
 
 // EqualOrMoreThan repeats i or i+1 or ... or n, prefer more.
-// It doesn't validate an input.
+// The count n should not be negative or greater than 1000,
+// otherwise ErrInvalidRepetition is reported.
 //
-// Regex: `{n,}`.
+// Regex: `{n,}`, `*` or `+`.
 func (r Repetition) EqualOrMoreThan(n int) dialect.Token {
-	return r.withSuffix(fmt.Sprintf("{%d,}", n))
+	if err := r.validateCount("EqualOrMoreThan", n); err != nil {
+		return r.withError(err)
+	}
+
+	return r.withBounds(n, unlimited, false)
 }
 
 // EqualOrMoreThanPreferFewer repeats i or i+1 or ... or n, prefer fewer.
-// It doesn't validate an input.
+// The count n should not be negative or greater than 1000,
+// otherwise ErrInvalidRepetition is reported.
 //
-// Regex: `{n,}?`.
+// Regex: `{n,}?`, `*?` or `+?`.
 func (r Repetition) EqualOrMoreThanPreferFewer(n int) dialect.Token {
-	return r.withSuffix(fmt.Sprintf("{%d,}?", n))
+	if err := r.validateCount("EqualOrMoreThanPreferFewer", n); err != nil {
+		return r.withError(err)
+	}
+
+	return r.withBounds(n, unlimited, true)
 }
 
 // Between repeats i=from or i+1 or ... or to, prefer more.
-// It doesn't validate an input.
+// Bounds should not be negative or greater than 1000 and from should
+// not be greater than to, otherwise ErrInvalidRepetition is reported.
 //
-// Regex: `{from,to}`.
+// Regex: `{from,to}`, `{n}` or `?`.
 func (r Repetition) Between(from, to int) dialect.Token {
-	return r.withSuffix(fmt.Sprintf("{%d,%d}", from, to))
+	if err := r.validateBounds("Between", from, to); err != nil {
+		return r.withError(err)
+	}
+
+	return r.withBounds(from, to, false)
 }
 
 // BetweenPreferFewer repeats i=from or i+1 or ... or to, prefer fewer.
-// It doesn't validate an input.
+// Bounds should not be negative or greater than 1000 and from should
+// not be greater than to, otherwise ErrInvalidRepetition is reported.
 //
-// Regex: `{from,to}?`.
+// Regex: `{from,to}?`, `{n}` or `??`.
 func (r Repetition) BetweenPreferFewer(from, to int) dialect.Token {
-	return r.withSuffix(fmt.Sprintf("{%d,%d}?", from, to))
+	if err := r.validateBounds("BetweenPreferFewer", from, to); err != nil {
+		return r.withError(err)
+	}
+
+	return r.withBounds(from, to, true)
 }
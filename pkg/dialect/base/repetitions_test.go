@@ -19,12 +19,12 @@ func TestRexClassRepetitions(t *testing.T) {
 
 	test.RexTestCasesSlice{{
 		Name:     "Between",
-		Chain:    []dialect.Token{getABClass().Between(0, 1)},
-		Expected: `[ab]{0,1}`,
+		Chain:    []dialect.Token{getABClass().Between(2, 3)},
+		Expected: `[ab]{2,3}`,
 	}, {
 		Name:     "BetweenPreferFewer",
-		Chain:    []dialect.Token{getABClass().BetweenPreferFewer(0, 1)},
-		Expected: `[ab]{0,1}?`,
+		Chain:    []dialect.Token{getABClass().BetweenPreferFewer(2, 3)},
+		Expected: `[ab]{2,3}?`,
 	}, {
 		Name:     "EqualOrMoreThan",
 		Chain:    []dialect.Token{getABClass().EqualOrMoreThan(5)},
@@ -73,5 +73,121 @@ func TestRexClassRepetitions(t *testing.T) {
 		Name:     "Exactly",
 		Chain:    []dialect.Token{base.Chars.Range('0', '9').Repeat().Exactly(2)},
 		Expected: `[0-9]{2}`,
+	}, {
+		Name:     "BetweenZeroOne",
+		Chain:    []dialect.Token{getABClass().Between(0, 1)},
+		Expected: `[ab]?`,
+	}, {
+		Name:     "BetweenZeroOnePreferFewer",
+		Chain:    []dialect.Token{getABClass().BetweenPreferFewer(0, 1)},
+		Expected: `[ab]??`,
+	}, {
+		Name:     "BetweenSame",
+		Chain:    []dialect.Token{getABClass().BetweenPreferFewer(3, 3)},
+		Expected: `[ab]{3}`,
+	}, {
+		Name:     "EqualOrMoreThanZero",
+		Chain:    []dialect.Token{getABClass().EqualOrMoreThan(0)},
+		Expected: `[ab]*`,
+	}, {
+		Name:     "EqualOrMoreThanOnePreferFewer",
+		Chain:    []dialect.Token{getABClass().EqualOrMoreThanPreferFewer(1)},
+		Expected: `[ab]+?`,
+	}, {
+		Name:     "ExactlyOne",
+		Chain:    []dialect.Token{getABClass().Exactly(1)},
+		Expected: `[ab]`,
+	}, {
+		Name:     "ExactlyLimit",
+		Chain:    []dialect.Token{getABClass().Exactly(1000)},
+		Expected: `[ab]{1000}`,
+	}, {
+		Name:     "RepeatRepeated",
+		Chain:    []dialect.Token{base.Common.Repeat(getABClass().OneOrMore()).Exactly(2)},
+		Expected: `(?:[ab]+){2}`,
+	}, {
+		Name:     "RepeatExactlyOne",
+		Chain:    []dialect.Token{base.Common.Repeat(getABClass().Exactly(1)).ZeroOrMore()},
+		Expected: `[ab]*`,
+	}, {
+		Name:     "RepeatNestedLimit",
+		Chain:    []dialect.Token{base.Common.Repeat(getABClass().Exactly(10)).Exactly(100)},
+		Expected: `(?:[ab]{10}){100}`,
+	}, {
+		Name:     "GroupNestedLimit",
+		Chain:    []dialect.Token{base.Group.Define(getABClass().Exactly(10)).Repeat().Exactly(100)},
+		Expected: `([ab]{10}){100}`,
+	}}.Run(t)
+}
+
+func TestRexRepetitions_errors(t *testing.T) {
+	getRepetition := func() base.Repetition {
+		return base.Chars.Digits().Repeat()
+	}
+
+	test.RexErrTestCasesSlice{{
+		Name:  "Exactly",
+		Chain: []dialect.Token{getRepetition().Exactly(-1)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "EqualOrMoreThan",
+		Chain: []dialect.Token{getRepetition().EqualOrMoreThan(-1)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "EqualOrMoreThanPreferFewer",
+		Chain: []dialect.Token{getRepetition().EqualOrMoreThanPreferFewer(-1)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Between_negative",
+		Chain: []dialect.Token{getRepetition().Between(-1, 2)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Between_reversed",
+		Chain: []dialect.Token{getRepetition().Between(3, 2)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "BetweenPreferFewer_reversed",
+		Chain: []dialect.Token{getRepetition().BetweenPreferFewer(3, 2)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Exactly_limit",
+		Chain: []dialect.Token{getRepetition().Exactly(1001)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "EqualOrMoreThan_limit",
+		Chain: []dialect.Token{getRepetition().EqualOrMoreThan(1001)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Between_limit",
+		Chain: []dialect.Token{getRepetition().Between(1, 1001)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Repeat_limit",
+		Chain: []dialect.Token{base.Common.Repeat(getRepetition().Exactly(10)).Exactly(101)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name: "Group_limit",
+		Chain: []dialect.Token{
+			base.Group.Define(getRepetition().Exactly(100)).Repeat().Exactly(100),
+		},
+		Err: base.ErrInvalidRepetition,
+	}, {
+		Name: "Common_repeat_limit",
+		Chain: []dialect.Token{
+			base.Common.Repeat(base.Group.NonCaptured(getRepetition().Exactly(100))).Exactly(100),
+		},
+		Err: base.ErrInvalidRepetition,
+	}, {
+		Name:  "Raw_limit",
+		Chain: []dialect.Token{base.Common.Repeat(base.Common.Raw(`(?:\d{100})`)).Exactly(100)},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Repeat_error",
+		Chain: []dialect.Token{base.Common.Repeat(getRepetition().Exactly(-1)).OneOrMore()},
+		Err:   base.ErrInvalidRepetition,
+	}, {
+		Name:  "Nested",
+		Chain: []dialect.Token{base.Group.Define(getRepetition().Exactly(-1))},
+		Err:   base.ErrInvalidRepetition,
 	}}.Run(t)
 }
@@ -5,8 +5,26 @@ import (
 )
 
 // Dialect specifies group of dialect tokens. It saves the name.
+//
+// It also names a syntax of regular expressions, see RegExp.StringFor.
 type Dialect string
 
+// Output dialects of regular expressions.
+const (
+	// RE2 is the syntax of Go regexp package.
+	RE2 Dialect = "RE2"
+	// PCRE is the syntax of PCRE2, PHP and Perl.
+	PCRE Dialect = "PCRE"
+	// ECMAScript is the syntax of JavaScript. The expression should
+	// be used with the `u` flag.
+	ECMAScript Dialect = "ECMAScript"
+	// POSIXExtended is POSIX ERE syntax, that is used by grep -E
+	// and PostgreSQL.
+	POSIXExtended Dialect = "POSIXExtended"
+	// DotNET is the syntax of System.Text.RegularExpressions.
+	DotNET Dialect = "DotNET"
+)
+
 // StringByteWriter is a union of io.ByteWriter and io.StringWriter.
 type StringByteWriter interface {
 	io.ByteWriter
@@ -28,3 +46,12 @@ type ClassToken interface {
 	// Example: [a-z] -> a-z.
 	Unwrap() ClassToken
 }
+
+// GroupNamer is an optional interface of StringByteWriter, that tracks
+// names of captured groups. Tokens, that write named groups, register
+// the name and write the returned one instead.
+type GroupNamer interface {
+	// GroupName registers the name and returns the name to write.
+	// It returns an error if the name cannot be used.
+	GroupName(name string) (string, error)
+}
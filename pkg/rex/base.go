@@ -1,6 +1,9 @@
 package rex
 
-import "github.com/hedhyw/rex/pkg/dialect/base"
+import (
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
 
 const (
 	// Chars is a namespace that contains character class elements.
@@ -49,6 +52,20 @@ const (
 	//     rex.Common.Text("rex"),
 	//   )) // (?:a|rex)
 	Group = base.Group
+	// Flags is a namespace that contains regular expression flags.
+	//
+	// Example usage:
+	//
+	//   rex.New(
+	//     rex.Flags.CaseInsensitive(),
+	//     rex.Common.Text("rex"),
+	//   ) // (?i)rex
+	//
+	//   rex.New(
+	//     rex.Flags.CaseInsensitive().Group(rex.Common.Text("r")),
+	//     rex.Common.Text("ex"),
+	//   ) // (?i:r)ex
+	Flags = base.Flags
 
 	// Helper is a namespace with common ready to use patterns.
 	//
@@ -60,3 +77,14 @@ const (
 	//   ).MustCompile().Match("+15555555")
 	Helper = base.Helper
 )
+
+// Repeat any token. The token is wrapped in a non-captured group only
+// if it is needed. It is an alias to base.Common.Repeat.
+//
+// Example usage:
+//
+//	rex.New(rex.Repeat(rex.Common.Text("ab")).OneOrMore()) // (?:ab)+
+//	rex.New(rex.Repeat(rex.Helper.IPv4()).Exactly(2))      // (?:...){2}
+func Repeat(token dialect.Token) base.Repetition {
+	return base.Common.Repeat(token)
+}
@@ -25,4 +25,23 @@ func TestBase(t *testing.T) {
 			t.Fatalf("Actual: %q, Expected: %q", rex.Common, base.Common)
 		}
 	})
+
+	t.Run("Flags", func(t *testing.T) {
+		t.Parallel()
+
+		if rex.Flags != base.Flags {
+			t.Fatalf("Actual: %q, Expected: %q", rex.Flags, base.Flags)
+		}
+	})
+
+	t.Run("Repeat", func(t *testing.T) {
+		t.Parallel()
+
+		const expected = `(?:ab){2}`
+
+		actual := rex.New(rex.Repeat(rex.Common.Text("ab")).Exactly(2)).String()
+		if actual != expected {
+			t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+		}
+	})
 }
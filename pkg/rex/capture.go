@@ -0,0 +1,94 @@
+package rex
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+// CaptureToken is a named captured group, that is bound to a type
+// of its value. Use rex.Capture for creating.
+type CaptureToken[T any] struct {
+	group  base.GroupToken
+	name   string
+	layout string
+}
+
+// Capture defines a named captured group, that can be used as a token
+// and as a handle for getting a typed value from the match. Referencing
+// the handle instead of a name or a position allows to find typos at
+// compile time and to reorder groups safely.
+//
+// Supported types are the same as in RegExp.Unmarshal.
+//
+// Example usage:
+//
+//	year := rex.Capture[int]("year", rex.Chars.Digits().Repeat().Exactly(4))
+//
+//	re := rex.New(rex.Common.Text("year: "), year)
+//
+//	match, _ := re.MatchNamed("year: 2022")
+//	year.Get(match) // 2022, true
+func Capture[T any](name string, tokens ...dialect.Token) CaptureToken[T] {
+	return CaptureToken[T]{
+		group:  base.Group.Define(tokens...).WithName(name),
+		name:   name,
+		layout: time.RFC3339,
+	}
+}
+
+// WithLayout sets the layout for parsing time.Time. It is RFC 3339 by default.
+func (ct CaptureToken[T]) WithLayout(layout string) CaptureToken[T] {
+	ct.layout = layout
+
+	return ct
+}
+
+// Name of the group.
+func (ct CaptureToken[T]) Name() string {
+	return ct.name
+}
+
+// Repeat the group.
+func (ct CaptureToken[T]) Repeat() base.Repetition {
+	return ct.group.Repeat()
+}
+
+// WriteTo implements dialect.Token interface.
+func (ct CaptureToken[T]) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	return ct.group.WriteTo(w)
+}
+
+// AST implements dialect.Node interface.
+func (ct CaptureToken[T]) AST() *dialect.AST {
+	return ct.group.AST()
+}
+
+// Get returns the value of the group from the match, that is returned
+// by RegExp.MatchNamed or RegExp.FindAllNamed. It returns false if the
+// group doesn't participate in the match or the value cannot be converted.
+func (ct CaptureToken[T]) Get(match map[string]string) (T, bool) {
+	val, err := ct.Parse(match)
+
+	return val, err == nil
+}
+
+// Parse is like Get, but returns an error. It returns ErrNoMatch if
+// the group doesn't participate in the match.
+func (ct CaptureToken[T]) Parse(match map[string]string) (T, error) {
+	var val T
+
+	text, ok := match[ct.name]
+	if !ok {
+		return val, fmt.Errorf("group %q: %w", ct.name, ErrNoMatch)
+	}
+
+	if err := setValue(reflect.ValueOf(&val).Elem(), text, ct.layout); err != nil {
+		return val, fmt.Errorf("group %q: %w", ct.name, err)
+	}
+
+	return val, nil
+}
@@ -0,0 +1,92 @@
+package rex_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+func TestCapture(t *testing.T) {
+	t.Parallel()
+
+	var (
+		name = rex.Capture[string]("name", rex.Chars.Lower().Repeat().OneOrMore())
+		date = rex.Capture[time.Time]("date", rex.Common.Raw(`\d{4}-\d{2}-\d{2}`)).
+			WithLayout("2006-01-02")
+		count = rex.Capture[int]("count", rex.Chars.Digits().Repeat().OneOrMore())
+		flag  = rex.Capture[bool]("flag", rex.Common.Text("true"))
+	)
+
+	re := rex.New(
+		name,
+		rex.Chars.Single(' '),
+		date,
+		rex.Chars.Single(' '),
+		count,
+		flag.Repeat().ZeroOrOne(),
+	)
+
+	const expected = `(?P<name>[[:lower:]]+) (?P<date>\d{4}-\d{2}-\d{2}) (?P<count>\d+)(?P<flag>true)?`
+
+	if actual := re.String(); actual != expected {
+		t.Fatalf("Actual: %#q, Expected: %#q", actual, expected)
+	}
+
+	match, ok := re.MatchNamed("rex 2022-03-04 42")
+	if !ok {
+		t.Fatal("Expected match")
+	}
+
+	if actual, ok := name.Get(match); !ok || actual != "rex" {
+		t.Fatalf("Actual: %q, %v", actual, ok)
+	}
+
+	if actual, ok := date.Get(match); !ok || !actual.Equal(time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Actual: %v, %v", actual, ok)
+	}
+
+	if actual, ok := count.Get(match); !ok || actual != 42 {
+		t.Fatalf("Actual: %v, %v", actual, ok)
+	}
+
+	if actual, ok := flag.Get(match); ok || actual {
+		t.Fatalf("Actual: %v, %v", actual, ok)
+	}
+
+	if _, err := flag.Parse(match); !errors.Is(err, rex.ErrNoMatch) {
+		t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrNoMatch)
+	}
+
+	if actual := count.Name(); actual != "count" {
+		t.Fatalf("Actual: %q, Expected: %q", actual, "count")
+	}
+}
+
+func TestCaptureInvalidValue(t *testing.T) {
+	t.Parallel()
+
+	number := rex.Capture[uint8]("number", rex.Chars.Digits().Repeat().OneOrMore())
+
+	match, ok := rex.New(number).MatchNamed("256")
+	if !ok {
+		t.Fatal("Expected match")
+	}
+
+	if _, err := number.Parse(match); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if _, ok := number.Get(match); ok {
+		t.Fatal("Expected not ok")
+	}
+}
+
+func TestCaptureInvalidName(t *testing.T) {
+	t.Parallel()
+
+	if _, err := rex.NewE(rex.Capture[string]("", rex.Chars.Digits())); err == nil {
+		t.Fatal("Expected error")
+	}
+}
@@ -88,7 +88,7 @@ func Example_generalEmailCheck() {
 	fmt.Println("rex-lib@example.com.uk:", re.MatchString("rex-lib@example.com.uk"))
 	fmt.Println("rexexample.com:", re.MatchString("rexexample.com"))
 	// Output:
-	// regular expression: ^[a-z[:upper:]\x2D\d]+@[\.a-z[:upper:]\x2D\d]+\.[[:alnum:]]{2,3}$
+	// regular expression: ^[\x2D0-9A-Za-z]+@[\x2D\.0-9A-Za-z]+\.[[:alnum:]]{2,3}$
 	// rex-lib@example.com.uk: true
 	// rexexample.com: false
 }
@@ -380,7 +380,7 @@ func Example_emailMatch() {
 	fmt.Println("@example.com:", re.MatchString("@example.com"))
 
 	// Output:
-	// regular expression: ^(?:(?:[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~](?:[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~\.]?[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~]){0,31})@(?:[[:alnum:]][[:alnum:]\x2D]{0,62}(?:\.*[[:alnum:]][[:alnum:]\x2D]{0,62})*[[:alnum:]]))$
+	// regular expression: ^(?:(?:[!#-'\*\+\x2D/-9=\?A-Z\^-~](?:[!#-'\*\+\x2D-9=\?A-Z\^-~]?[!#-'\*\+\x2D/-9=\?A-Z\^-~]){0,31})@(?:[[:alnum:]][[:alnum:]\x2D]{0,62}(?:\.*[[:alnum:]][[:alnum:]\x2D]{0,62})*[[:alnum:]]))$
 	// example@example.com: true
 	// @example.com: false
 }
@@ -405,7 +405,7 @@ func Example_emailFind() {
 	}
 
 	// Output:
-	// regular expression: (?P<email>(?:(?:[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~](?:[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~\.]?[[:alnum:]!#\$\x25&'\*\+\x2D/=\?\^_`\{\|\}~]){0,31})@(?:[[:alnum:]][[:alnum:]\x2D]{0,62}(?:\.*[[:alnum:]][[:alnum:]\x2D]{0,62})*[[:alnum:]])))
+	// regular expression: (?P<email>(?:(?:[!#-'\*\+\x2D/-9=\?A-Z\^-~](?:[!#-'\*\+\x2D-9=\?A-Z\^-~]?[!#-'\*\+\x2D/-9=\?A-Z\^-~]){0,31})@(?:[[:alnum:]][[:alnum:]\x2D]{0,62}(?:\.*[[:alnum:]][[:alnum:]\x2D]{0,62})*[[:alnum:]])))
 	// submatches[0]: duyen@example.com
 	// submatches[1]: rex@rex.example.com
 }
@@ -451,3 +451,52 @@ func Example_numberRange() {
 	// -2: false
 	// 124: false
 }
+
+func Example_flags() {
+	re := rex.New(
+		rex.Chars.Begin(),
+		rex.Flags.CaseInsensitive().Group(rex.Common.Text("hello")),
+		rex.Chars.Whitespace(),
+		rex.Common.Text("rex"),
+		rex.Chars.End(),
+	).MustCompile()
+
+	fmt.Println("regular expression:", re.String())
+	fmt.Println("Hello rex:", re.MatchString("Hello rex"))
+	fmt.Println("HELLO rex:", re.MatchString("HELLO rex"))
+	fmt.Println("hello REX:", re.MatchString("hello REX"))
+
+	// Output:
+	// regular expression: ^(?i:hello)\srex$
+	// Hello rex: true
+	// HELLO rex: true
+	// hello REX: false
+}
+
+func Example_unmarshal() {
+	re := rex.New(
+		rex.Chars.Begin(),
+		rex.Group.Define(rex.Chars.Upper().Repeat().OneOrMore()).WithName("level"),
+		rex.Chars.Whitespace(),
+		rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("code"),
+		rex.Chars.End(),
+	)
+
+	values, ok := re.MatchNamed("ERROR 500")
+	fmt.Println("values:", values, ok)
+
+	var entry struct {
+		Level string `rex:"level"`
+		Code  int    `rex:"code"`
+	}
+
+	if err := re.Unmarshal("ERROR 500", &entry); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("entry: %+v\n", entry)
+
+	// Output:
+	// values: map[code:500 level:ERROR] true
+	// entry: {Level:ERROR Code:500}
+}
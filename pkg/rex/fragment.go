@@ -0,0 +1,169 @@
+package rex
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/helper"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+// NamespaceSeparator separates a namespace of a fragment and a name
+// of a group: `src__octet`. Regular expressions allow only word characters
+// in names, so the dot can't be used.
+const NamespaceSeparator = "__"
+
+// FragmentToken is a reusable sequence of tokens. Names of groups
+// inside the fragment are prefixed with its namespace.
+// Use rex.Fragment for creating.
+type FragmentToken struct {
+	tokens    []dialect.Token
+	namespace string
+	err       error
+}
+
+// Fragment defines a reusable sequence of tokens. Use FragmentToken.As
+// for using the same fragment multiple times without clashes of
+// group names.
+//
+// Example usage:
+//
+//	ip := rex.Fragment(
+//	  rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("octet1"),
+//	  // ...
+//	)
+//
+//	re := rex.New(ip.As("src"), rex.Common.Text(" -> "), ip.As("dst"))
+//	// (?P<src__octet1>\d+) -> (?P<dst__octet1>\d+)
+//
+//	match, _ := re.MatchNested("1 -> 2")
+//	match.Get("src.octet1") // "1", true
+func Fragment(tokens ...dialect.Token) FragmentToken {
+	return FragmentToken{
+		tokens:    helper.GroupAlternations(tokens, nonCaptured),
+		namespace: "",
+		err:       nil,
+	}
+}
+
+// As returns the fragment with names of groups prefixed by the namespace.
+// The namespace should contain only word characters and should not
+// contain NamespaceSeparator, otherwise base.ErrInvalidGroupName is
+// reported. Fragments can be nested: `outer__inner__name`. Names of
+// groups can't contain NamespaceSeparator if fragments are used.
+func (ft FragmentToken) As(namespace string) FragmentToken {
+	ft.namespace = namespace
+	ft.err = nil
+
+	if !helper.IsWord(namespace) || strings.Contains(namespace, NamespaceSeparator) {
+		ft.err = fmt.Errorf("%w: Fragment.As(%q): invalid namespace", base.ErrInvalidGroupName, namespace)
+	}
+
+	return ft
+}
+
+// Repeat the fragment.
+func (ft FragmentToken) Repeat() base.Repetition {
+	return base.Group.NonCaptured(ft).Repeat()
+}
+
+// WriteTo implements dialect.Token interface.
+func (ft FragmentToken) WriteTo(w dialect.StringByteWriter) (n int, err error) {
+	tokens := ft.tokens
+
+	if ft.err != nil {
+		tokens = append(tokens[:len(tokens):len(tokens)], helper.ErrorToken(ft.err))
+	}
+
+	if ft.namespace == "" || ft.err != nil {
+		return helper.ProcessTokens(w, tokens)
+	}
+
+	return helper.ProcessTokens(namespacedWriter{
+		StringByteWriter: w,
+		namespace:        ft.namespace,
+	}, tokens)
+}
+
+// AST implements dialect.Node interface.
+func (ft FragmentToken) AST() *dialect.AST {
+	node := dialect.ConcatAST(ft.tokens...)
+
+	if ft.namespace == "" || ft.err != nil {
+		return node
+	}
+
+	node.Walk(func(node *dialect.AST) bool {
+		if node.Kind == dialect.KindGroup && node.Name != "" {
+			node.Name = ft.namespace + NamespaceSeparator + node.Name
+		}
+
+		return true
+	})
+
+	return node
+}
+
+// namespacedWriter prefixes names of groups. It implements
+// dialect.GroupNamer.
+type namespacedWriter struct {
+	dialect.StringByteWriter
+
+	namespace string
+}
+
+// GroupName implements dialect.GroupNamer. Names of groups inside
+// the fragment can't contain NamespaceSeparator.
+func (nw namespacedWriter) GroupName(name string) (string, error) {
+	if strings.Contains(name, NamespaceSeparator) {
+		return name, fmt.Errorf(
+			"%w: name %q in Fragment.As(%q) contains %q",
+			base.ErrInvalidGroupName, name, nw.namespace, NamespaceSeparator,
+		)
+	}
+
+	return nw.namespacedGroupName(name)
+}
+
+// namespacedGroupName prefixes the name by namespaces of this and
+// outer fragments.
+func (nw namespacedWriter) namespacedGroupName(name string) (string, error) {
+	name = nw.namespace + NamespaceSeparator + name
+
+	switch namer := nw.StringByteWriter.(type) {
+	case namespacedGroupNamer:
+		return namer.namespacedGroupName(name)
+	case dialect.GroupNamer:
+		return namer.GroupName(name)
+	default:
+		return name, nil
+	}
+}
+
+// namespacedGroupNamer defines names of groups, that are already
+// prefixed with namespaces of fragments.
+type namespacedGroupNamer interface {
+	namespacedGroupName(name string) (string, error)
+}
+
+// Template is a parameterised fragment.
+//
+// Example usage:
+//
+//	number := rex.NewTemplate(func(digits int) []dialect.Token {
+//	  return []dialect.Token{
+//	    rex.Group.Define(rex.Chars.Digits().Repeat().Exactly(digits)).WithName("value"),
+//	  }
+//	})
+//
+//	rex.New(number(4).As("year"), rex.Common.Text("-"), number(2).As("month"))
+//	// (?P<year__value>\d{4})-(?P<month__value>\d{2})
+type Template[P any] func(params P) FragmentToken
+
+// NewTemplate creates a template, that builds fragments from parameters.
+func NewTemplate[P any](build func(params P) []dialect.Token) Template[P] {
+	return func(params P) FragmentToken {
+		return Fragment(build(params)...)
+	}
+}
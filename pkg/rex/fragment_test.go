@@ -0,0 +1,187 @@
+package rex_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hedhyw/rex/internal/test"
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+func getIPFragment() rex.FragmentToken {
+	octet := func(name string) dialect.Token {
+		return rex.Group.Define(rex.Chars.Digits().Repeat().Between(1, 3)).WithName(name)
+	}
+
+	return rex.Fragment(
+		octet("octet1"), rex.Chars.Single('.'),
+		octet("octet2"), rex.Chars.Single('.'),
+		octet("octet3"), rex.Chars.Single('.'),
+		octet("octet4"),
+	)
+}
+
+func TestFragment(t *testing.T) {
+	ip := getIPFragment()
+
+	test.RexTestCasesSlice{{
+		Name:     "without_namespace",
+		Chain:    []dialect.Token{rex.Fragment(rex.Chars.Digits(), rex.Chars.Single('a'))},
+		Expected: `\da`,
+	}, {
+		Name: "namespaces",
+		Chain: []dialect.Token{
+			ip.As("src"),
+			rex.Common.Text(" -> "),
+			ip.As("dst"),
+		},
+		Expected: `(?P<src__octet1>\d{1,3})\.(?P<src__octet2>\d{1,3})\.(?P<src__octet3>\d{1,3})\.(?P<src__octet4>\d{1,3})` +
+			` -> ` +
+			`(?P<dst__octet1>\d{1,3})\.(?P<dst__octet2>\d{1,3})\.(?P<dst__octet3>\d{1,3})\.(?P<dst__octet4>\d{1,3})`,
+	}, {
+		Name: "nested",
+		Chain: []dialect.Token{
+			rex.Fragment(
+				rex.Group.Define(rex.Chars.Single('a')).WithName("name"),
+				rex.Fragment(
+					rex.Group.Define(rex.Chars.Single('b')).WithName("name"),
+				).As("inner"),
+			).As("outer"),
+		},
+		Expected: `(?P<outer__name>a)(?P<outer__inner__name>b)`,
+	}, {
+		Name: "repeat",
+		Chain: []dialect.Token{
+			rex.Fragment(rex.Chars.Single('a'), rex.Chars.Single('b')).Repeat().OneOrMore(),
+		},
+		Expected: `(?:ab)+`,
+	}}.Run(t)
+}
+
+func TestFragmentErrors(t *testing.T) {
+	ip := getIPFragment()
+
+	test.RexErrTestCasesSlice{{
+		Name:  "duplicate_namespace",
+		Chain: []dialect.Token{ip.As("src"), ip.As("src")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name:  "empty_namespace",
+		Chain: []dialect.Token{ip.As("")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name:  "invalid_namespace",
+		Chain: []dialect.Token{ip.As("a.b")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name:  "separator_in_namespace",
+		Chain: []dialect.Token{ip.As("a__b")},
+		Err:   base.ErrInvalidGroupName,
+	}, {
+		Name: "separator_in_fragment_group",
+		Chain: []dialect.Token{rex.Fragment(
+			rex.Group.Define(rex.Chars.Digits()).WithName("a__b"),
+		).As("src")},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "separator_before_fragment",
+		Chain: []dialect.Token{
+			rex.Group.Define(rex.Chars.Digits()).WithName("src__octet1"),
+			ip.As("dst"),
+		},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "separator_after_fragment",
+		Chain: []dialect.Token{
+			ip.As("dst"),
+			rex.Group.Define(rex.Chars.Digits()).WithName("src__octet1"),
+		},
+		Err: base.ErrInvalidGroupName,
+	}, {
+		Name: "nested_repetition_limit",
+		Chain: []dialect.Token{
+			rex.Fragment(rex.Chars.Digits().Repeat().Exactly(100)).Repeat().Exactly(100),
+		},
+		Err: base.ErrInvalidRepetition,
+	}}.Run(t)
+}
+
+func TestFragmentAST(t *testing.T) {
+	t.Parallel()
+
+	re := rex.New(rex.Fragment(
+		rex.Group.Define(rex.Chars.Single('a')).WithName("name"),
+	).As("ns"))
+
+	const expected = `Group<ns__name>(Literal("a"))`
+
+	if actual := test.FormatAST(re.AST()); actual != expected {
+		t.Fatalf("Actual: %s, Expected: %s", actual, expected)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	number := rex.NewTemplate(func(digits int) []dialect.Token {
+		return []dialect.Token{
+			rex.Group.Define(rex.Chars.Digits().Repeat().Exactly(digits)).WithName("value"),
+		}
+	})
+
+	re := rex.New(number(4).As("year"), rex.Common.Text("-"), number(2).As("month"))
+
+	const expected = `(?P<year__value>\d{4})-(?P<month__value>\d{2})`
+
+	if actual := re.String(); actual != expected {
+		t.Fatalf("Actual: %#q, Expected: %#q", actual, expected)
+	}
+}
+
+func TestMatchNested(t *testing.T) {
+	t.Parallel()
+
+	ip := getIPFragment()
+	re := rex.New(ip.As("src"), rex.Common.Text(" -> "), ip.As("dst"))
+
+	match, ok := re.MatchNested("10.0.0.1 -> 192.168.0.2")
+	if !ok {
+		t.Fatal("Expected match")
+	}
+
+	for path, expected := range map[string]string{
+		"src.octet1": "10",
+		"src.octet4": "1",
+		"dst.octet1": "192",
+		"dst.octet4": "2",
+	} {
+		if actual, ok := match.Get(path); !ok || actual != expected {
+			t.Fatalf("%s: Actual: %q, %v, Expected: %q", path, actual, ok, expected)
+		}
+	}
+
+	if actual := match.Namespaces["dst"].Values["octet2"]; actual != "168" {
+		t.Fatalf("Actual: %q, Expected: %q", actual, "168")
+	}
+
+	for _, path := range []string{"src", "src.octet5", "unknown.octet1"} {
+		if _, ok := match.Get(path); ok {
+			t.Fatalf("%s: Expected not ok", path)
+		}
+	}
+
+	if _, ok := re.MatchNested("none"); ok {
+		t.Fatal("Expected no match")
+	}
+}
+
+func TestFragmentUnwrapErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := rex.NewE(getIPFragment().As("-"))
+	if !errors.Is(err, base.ErrInvalidGroupName) {
+		t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidGroupName)
+	}
+}
@@ -0,0 +1,197 @@
+package rex
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNoMatch is returned by Unmarshal if the text doesn't match
+// the regular expression.
+var ErrNoMatch = errors.New("no match")
+
+// ErrInvalidUnmarshal is returned by Unmarshal if the target is not
+// a non-nil pointer to a struct.
+var ErrInvalidUnmarshal = errors.New("invalid unmarshal target")
+
+// MatchNamed returns values of named groups of the leftmost match.
+// Groups that don't participate in the match are not included.
+// The second result reports whether the text matches.
+//
+// It compiles the regular expression once and panics if it cannot
+// be compiled, like MustCompile.
+//
+// Example usage:
+//
+//	values, ok := rex.New(
+//	  rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("id"),
+//	).MatchNamed("id: 123") // map[id:123], true
+func (r RegExp) MatchNamed(s string) (map[string]string, bool) {
+	re := r.mustCachedCompile()
+
+	indexes := re.FindStringSubmatchIndex(s)
+	if indexes == nil {
+		return nil, false
+	}
+
+	return namedSubmatches(re.SubexpNames(), s, indexes), true
+}
+
+// FindAllNamed returns values of named groups of successive matches.
+// The argument n has the same meaning as in regexp.FindAllString:
+// if n < 0, it returns all matches.
+//
+// It compiles the regular expression once and panics if it cannot
+// be compiled, like MustCompile.
+func (r RegExp) FindAllNamed(s string, n int) []map[string]string {
+	re := r.mustCachedCompile()
+
+	allIndexes := re.FindAllStringSubmatchIndex(s, n)
+	if allIndexes == nil {
+		return nil
+	}
+
+	names := re.SubexpNames()
+	result := make([]map[string]string, 0, len(allIndexes))
+
+	for _, indexes := range allIndexes {
+		result = append(result, namedSubmatches(names, s, indexes))
+	}
+
+	return result
+}
+
+// Unmarshal matches the text and stores values of named groups into
+// fields of the struct pointed to by v. Fields are bound to groups
+// using the tag `rex:"group_name"`.
+//
+// Supported field types are string, bool, integers, floats, time.Duration,
+// time.Time and types that implement encoding.TextUnmarshaler, as well
+// as pointers to them. The layout of time.Time is RFC 3339 by default,
+// it can be changed by the tag option: `rex:"date,layout=2006-01-02"`.
+//
+// Fields of groups that don't participate in the match are not changed.
+// It returns ErrNoMatch if the text doesn't match and ErrInvalidUnmarshal
+// if a tag refers to a group that is not in the expression.
+//
+// Example usage:
+//
+//	var entry struct {
+//	  Level string `rex:"level"`
+//	  Code  int    `rex:"code"`
+//	}
+//
+//	err := re.Unmarshal("ERROR 500", &entry)
+func (r RegExp) Unmarshal(s string, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", ErrInvalidUnmarshal, v)
+	}
+
+	re, err := r.cachedCompile()
+	if err != nil {
+		return err
+	}
+
+	if err := checkTags(target.Elem().Type(), re.SubexpNames()); err != nil {
+		return err
+	}
+
+	indexes := re.FindStringSubmatchIndex(s)
+	if indexes == nil {
+		return ErrNoMatch
+	}
+
+	return unmarshalStruct(target.Elem(), namedSubmatches(re.SubexpNames(), s, indexes))
+}
+
+// namedSubmatches maps names of groups to matched values.
+func namedSubmatches(names []string, s string, indexes []int) map[string]string {
+	values := make(map[string]string, len(names))
+
+	for i, name := range names {
+		if name == "" || indexes[2*i] < 0 {
+			continue
+		}
+
+		values[name] = s[indexes[2*i]:indexes[2*i+1]]
+	}
+
+	return values
+}
+
+// Submatches are values of named groups, grouped by namespaces
+// of fragments. See rex.Fragment.
+type Submatches struct {
+	// Values of groups of the current namespace by names.
+	Values map[string]string
+	// Namespaces are nested fragments by their namespaces.
+	Namespaces map[string]Submatches
+}
+
+// NestSubmatches groups values, that are returned by RegExp.MatchNamed
+// or RegExp.FindAllNamed, by namespaces. Names are split by
+// NamespaceSeparator, so it is not allowed in names of groups of
+// expressions with fragments.
+func NestSubmatches(match map[string]string) Submatches {
+	root := newSubmatches()
+
+	for name, value := range match {
+		current := root
+		path := strings.Split(name, NamespaceSeparator)
+
+		for _, namespace := range path[:len(path)-1] {
+			nested, ok := current.Namespaces[namespace]
+			if !ok {
+				nested = newSubmatches()
+				current.Namespaces[namespace] = nested
+			}
+
+			current = nested
+		}
+
+		current.Values[path[len(path)-1]] = value
+	}
+
+	return root
+}
+
+func newSubmatches() Submatches {
+	return Submatches{
+		Values:     make(map[string]string),
+		Namespaces: make(map[string]Submatches),
+	}
+}
+
+// Get returns a value by a path, where namespaces are separated by
+// dots: `src.octet1`. The second result is false, if the group doesn't
+// participate in the match.
+func (s Submatches) Get(path string) (string, bool) {
+	current := s
+	parts := strings.Split(path, ".")
+
+	for _, namespace := range parts[:len(parts)-1] {
+		nested, ok := current.Namespaces[namespace]
+		if !ok {
+			return "", false
+		}
+
+		current = nested
+	}
+
+	value, ok := current.Values[parts[len(parts)-1]]
+
+	return value, ok
+}
+
+// MatchNested is like MatchNamed, but groups values by namespaces of
+// fragments, see NestSubmatches.
+func (r RegExp) MatchNested(s string) (Submatches, bool) {
+	match, ok := r.MatchNamed(s)
+	if !ok {
+		return newSubmatches(), false
+	}
+
+	return NestSubmatches(match), true
+}
@@ -0,0 +1,236 @@
+package rex_test
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+func getLogRegExp() *rex.RegExp {
+	return rex.New(
+		rex.Group.Define(rex.Chars.Upper().Repeat().OneOrMore()).WithName("level"),
+		rex.Chars.Whitespace(),
+		rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("code"),
+		rex.Group.NonCaptured(
+			rex.Chars.Whitespace(),
+			rex.Group.Define(rex.Chars.Lower().Repeat().OneOrMore()).WithName("message"),
+		).Repeat().ZeroOrOne(),
+	)
+}
+
+func TestRexMatchNamed(t *testing.T) {
+	t.Parallel()
+
+	re := getLogRegExp()
+
+	t.Run("matched", func(t *testing.T) {
+		t.Parallel()
+
+		actual, ok := re.MatchNamed("> ERROR 500 failed")
+		expected := map[string]string{"level": "ERROR", "code": "500", "message": "failed"}
+
+		if !ok || !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+		}
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		t.Parallel()
+
+		actual, ok := re.MatchNamed("INFO 200")
+		expected := map[string]string{"level": "INFO", "code": "200"}
+
+		if !ok || !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+		}
+	})
+
+	t.Run("not_matched", func(t *testing.T) {
+		t.Parallel()
+
+		if actual, ok := re.MatchNamed("info"); ok || actual != nil {
+			t.Fatalf("Actual: %q, %v", actual, ok)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		var recovered interface{}
+
+		func() {
+			defer func() { recovered = recover() }()
+
+			_, _ = rex.New(rex.Common.Raw(`[a-`)).MatchNamed("a")
+		}()
+
+		if recovered == nil {
+			t.Fatal("Expected panic")
+		}
+	})
+}
+
+func TestRexFindAllNamed(t *testing.T) {
+	t.Parallel()
+
+	re := getLogRegExp()
+
+	actual := re.FindAllNamed("INFO 200 ok; ERROR 500; WARN 300", 2)
+	expected := []map[string]string{
+		{"level": "INFO", "code": "200", "message": "ok"},
+		{"level": "ERROR", "code": "500"},
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Actual: %q, Expected: %q", actual, expected)
+	}
+
+	if actual := re.FindAllNamed("none", -1); actual != nil {
+		t.Fatalf("Actual: %q, Expected: nil", actual)
+	}
+}
+
+func TestRexUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	re := rex.New(
+		rex.Group.Define(rex.Chars.Lower().Repeat().OneOrMore()).WithName("name"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(rex.Common.Raw(`-?\d+`)).WithName("int"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(rex.Common.Raw(`\d+\.\d+`)).WithName("float"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(rex.Common.Raw(`true|false`)).WithName("bool"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(
+			rex.Group.Define(rex.Common.Raw(`\d{4}`)).WithName("year"),
+			rex.Common.Raw(`-\d{2}-\d{2}`),
+		).WithName("date"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(rex.Common.Raw(`\d+s`)).WithName("duration"),
+		rex.Chars.Single(' '),
+		rex.Group.Define(rex.Common.Raw(`[\d.]+`)).WithName("ip"),
+		rex.Group.Define(rex.Chars.Single('!')).WithName("missing").Repeat().ZeroOrOne(),
+	)
+
+	type target struct {
+		Name     string        `rex:"name"`
+		Int      int16         `rex:"int"`
+		Float    float64       `rex:"float"`
+		Bool     bool          `rex:"bool"`
+		Date     time.Time     `rex:"date,layout=2006-01-02"`
+		Duration time.Duration `rex:"duration"`
+		IP       net.IP        `rex:"ip"`
+		Missing  string        `rex:"missing"`
+		Skipped  string        `rex:"-"`
+		Untagged string
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		var actual struct {
+			Name     *string       `rex:"name"`
+			Int      int16         `rex:"int"`
+			Uint     uint          `rex:"year"`
+			Float    float32       `rex:"float"`
+			Bool     bool          `rex:"bool"`
+			Date     time.Time     `rex:"date,layout=2006-01-02"`
+			Duration time.Duration `rex:"duration"`
+			IP       net.IP        `rex:"ip"`
+			Missing  string        `rex:"missing"`
+			Skipped  string        `rex:"-"`
+			Untagged string
+		}
+
+		actual.Missing = "default"
+
+		err := re.Unmarshal("rex -12 3.5 true 2022-03-04 5s 127.0.0.1", &actual)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		switch {
+		case actual.Name == nil || *actual.Name != "rex":
+			t.Fatalf("Name: %v", actual.Name)
+		case actual.Int != -12:
+			t.Fatalf("Int: %v", actual.Int)
+		case actual.Uint != 2022:
+			t.Fatalf("Uint: %v", actual.Uint)
+		case actual.Float != 3.5:
+			t.Fatalf("Float: %v", actual.Float)
+		case !actual.Bool:
+			t.Fatalf("Bool: %v", actual.Bool)
+		case !actual.Date.Equal(time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)):
+			t.Fatalf("Date: %v", actual.Date)
+		case actual.Duration != 5*time.Second:
+			t.Fatalf("Duration: %v", actual.Duration)
+		case !actual.IP.Equal(net.IPv4(127, 0, 0, 1)):
+			t.Fatalf("IP: %v", actual.IP)
+		case actual.Missing != "default":
+			t.Fatalf("Missing: %v", actual.Missing)
+		}
+	})
+
+	t.Run("no_match", func(t *testing.T) {
+		t.Parallel()
+
+		var actual target
+
+		if err := re.Unmarshal("none", &actual); !errors.Is(err, rex.ErrNoMatch) {
+			t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrNoMatch)
+		}
+	})
+
+	t.Run("invalid_target", func(t *testing.T) {
+		t.Parallel()
+
+		var actual target
+
+		if err := re.Unmarshal("none", actual); !errors.Is(err, rex.ErrInvalidUnmarshal) {
+			t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrInvalidUnmarshal)
+		}
+	})
+
+	t.Run("unsupported_type", func(t *testing.T) {
+		t.Parallel()
+
+		var actual struct {
+			Name []int `rex:"name"`
+		}
+
+		err := re.Unmarshal("rex -12 3.5 true 2022-03-04 5s 127.0.0.1", &actual)
+		if !errors.Is(err, rex.ErrInvalidUnmarshal) {
+			t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrInvalidUnmarshal)
+		}
+	})
+
+	t.Run("unknown_group", func(t *testing.T) {
+		t.Parallel()
+
+		var actual struct {
+			Name string `rex:"nmae"`
+		}
+
+		// The tag is checked even if the text doesn't match.
+		err := re.Unmarshal("none", &actual)
+		if !errors.Is(err, rex.ErrInvalidUnmarshal) || !strings.Contains(err.Error(), `"nmae"`) {
+			t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrInvalidUnmarshal)
+		}
+	})
+
+	t.Run("compile_error", func(t *testing.T) {
+		t.Parallel()
+
+		var actual target
+
+		if err := rex.New(rex.Common.Raw(`[a-`)).Unmarshal("a", &actual); err == nil {
+			t.Fatal("Expected error")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package rex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+)
+
+// groupNames tracks names of captured groups. It implements
+// dialect.GroupNamer.
+type groupNames struct {
+	*strings.Builder
+
+	// offsets of defined groups by names.
+	offsets map[string]int
+	// unique renames duplicates instead of reporting an error.
+	unique bool
+	// namespaced is true if a group of a fragment is defined.
+	namespaced bool
+	// separated is the first name, that contains NamespaceSeparator
+	// outside fragments.
+	separated string
+}
+
+func newGroupNames(sb *strings.Builder, unique bool) *groupNames {
+	return &groupNames{
+		Builder: sb,
+		offsets: make(map[string]int),
+		unique:  unique,
+	}
+}
+
+// GroupName implements dialect.GroupNamer. Names can't contain
+// NamespaceSeparator if fragments are used, otherwise NestSubmatches
+// would nest them wrongly.
+func (gn *groupNames) GroupName(name string) (string, error) {
+	if strings.Contains(name, NamespaceSeparator) {
+		if gn.namespaced {
+			return name, fmt.Errorf(
+				"%w: name %q contains %q, that separates namespaces of fragments",
+				base.ErrInvalidGroupName, name, NamespaceSeparator,
+			)
+		}
+
+		if gn.separated == "" {
+			gn.separated = name
+		}
+	}
+
+	return gn.define(name)
+}
+
+// namespacedGroupName defines the name of the group of a fragment,
+// that is prefixed with namespaces.
+func (gn *groupNames) namespacedGroupName(name string) (string, error) {
+	if gn.separated != "" {
+		return name, fmt.Errorf(
+			"%w: name %q contains %q, that separates namespaces of fragments",
+			base.ErrInvalidGroupName, gn.separated, NamespaceSeparator,
+		)
+	}
+
+	gn.namespaced = true
+
+	return gn.define(name)
+}
+
+func (gn *groupNames) define(name string) (string, error) {
+	if first, ok := gn.offsets[name]; ok {
+		if !gn.unique {
+			return name, fmt.Errorf(
+				"%w: duplicate name %q at offset %d, first defined at offset %d",
+				base.ErrInvalidGroupName, name, gn.Len(), first,
+			)
+		}
+
+		name = gn.uniqueName(name)
+	}
+
+	gn.offsets[name] = gn.Len()
+
+	return name, nil
+}
+
+// uniqueName returns `name_N` with the lowest N, that is not used.
+func (gn *groupNames) uniqueName(name string) string {
+	for i := 2; ; i++ {
+		candidate := name + "_" + strconv.Itoa(i)
+
+		if _, ok := gn.offsets[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// renameGroups applies the same renaming to the tree as groupNames
+// applies to the written expression.
+func renameGroups(node *dialect.AST) {
+	gn := newGroupNames(&strings.Builder{}, true)
+
+	node.Walk(func(node *dialect.AST) bool {
+		if node.Kind == dialect.KindGroup && node.Name != "" {
+			node.Name, _ = gn.GroupName(node.Name)
+		}
+
+		return true
+	})
+}
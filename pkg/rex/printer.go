@@ -0,0 +1,824 @@
+package rex
+
+import (
+	"fmt"
+	"reflect"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// StringFor returns the regular expression in the syntax of the dialect:
+// dialect.RE2, dialect.PCRE, dialect.ECMAScript, dialect.POSIXExtended
+// or dialect.DotNET. Flags are applied while printing, so the result
+// doesn't contain inline flags.
+//
+// It returns ErrUnsupportedSyntax if a construct can't be expressed
+// in the dialect. For example, POSIX ERE doesn't have named groups.
+//
+// Example usage:
+//
+//	rex.New(
+//	  rex.Group.Define(rex.Chars.Digits()).WithName("digit"),
+//	).StringFor(dialect.ECMAScript) // (?<digit>\d)
+func (r RegExp) StringFor(d dialect.Dialect) (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+
+	switch d {
+	case dialect.RE2:
+		if err := checkRE2(r.AST()); err != nil {
+			return "", err
+		}
+
+		return r.String(), nil
+	case dialect.PCRE, dialect.ECMAScript, dialect.POSIXExtended, dialect.DotNET:
+	default:
+		return "", fmt.Errorf("%w: unknown dialect %q", ErrUnsupportedSyntax, d)
+	}
+
+	p := printer{
+		dialect: d,
+		flags:   syntax.Perl,
+	}
+
+	out, err := p.Print(r.AST())
+	if err != nil {
+		return "", err
+	}
+
+	return out.text, nil
+}
+
+// precedence of a printed expression. Expressions with lower precedence
+// are wrapped in a group when they are used as a part of expressions
+// with higher precedence.
+type precedence uint8
+
+const (
+	precAlternate precedence = iota
+	precConcat
+	precRepeat
+	precAtom
+)
+
+type printed struct {
+	text string
+	prec precedence
+}
+
+func atom(text string) printed {
+	return printed{text: text, prec: precAtom}
+}
+
+// wordClass is `\w` that doesn't depend on the dialect.
+const wordClass = `[0-9A-Za-z_]`
+
+// posixClassNames are classes supported by POSIX ERE.
+var posixClassNames = map[string]bool{
+	"alnum": true, "alpha": true, "blank": true, "cntrl": true,
+	"digit": true, "graph": true, "lower": true, "print": true,
+	"punct": true, "space": true, "upper": true, "xdigit": true,
+}
+
+// printer converts dialect.AST to the syntax of the dialect. It tracks
+// flags like syntaxConverter, flags are not printed.
+type printer struct {
+	dialect dialect.Dialect
+	flags   syntax.Flags
+}
+
+func (p *printer) unsupported(construct string) error {
+	return fmt.Errorf("%w: %s: %s", ErrUnsupportedSyntax, p.dialect, construct)
+}
+
+// Print the tree. The method is not safe for concurrent use.
+//
+// nolint: cyclop // One case per a kind.
+func (p *printer) Print(node *dialect.AST) (printed, error) {
+	switch node.Kind {
+	case dialect.KindEmpty:
+		return printed{text: "", prec: precConcat}, nil
+	case dialect.KindFlags:
+		p.flags = applySyntaxFlags(p.flags, node.Flags)
+
+		return printed{text: "", prec: precConcat}, nil
+	case dialect.KindRaw:
+		return p.printRaw(node)
+	case dialect.KindLiteral:
+		return p.printLiteral(node.Value), nil
+	case dialect.KindClass:
+		return p.printClass(node)
+	case dialect.KindAnyChar:
+		return p.printAnyChar(p.flags&syntax.DotNL != 0), nil
+	case dialect.KindBegin:
+		return p.printBegin(p.flags&syntax.OneLine == 0)
+	case dialect.KindEnd:
+		return p.printEnd(p.flags&syntax.OneLine == 0)
+	case dialect.KindBeginOfText:
+		return p.printBegin(false)
+	case dialect.KindEndOfText:
+		return p.printEnd(false)
+	case dialect.KindWordBoundary, dialect.KindNoWordBoundary:
+		return p.printWordBoundary(node.Kind == dialect.KindNoWordBoundary)
+	case dialect.KindConcat:
+		return p.printConcat(node.Sub)
+	case dialect.KindAlternate:
+		return p.printAlternate(node.Sub)
+	case dialect.KindGroup:
+		return p.printGroup(node)
+	case dialect.KindRepeat:
+		return p.printRepeat(node)
+	case dialect.KindLookAhead, dialect.KindLookBehind, dialect.KindAtomic:
+		return p.printAssertion(node)
+	case dialect.KindBackRef:
+		return p.printBackRef(node)
+	default:
+		return printed{}, p.unsupported(node.Kind.String())
+	}
+}
+
+func (p *printer) printAssertion(node *dialect.AST) (printed, error) {
+	var prefix string
+
+	switch {
+	case p.dialect == dialect.POSIXExtended,
+		p.dialect == dialect.ECMAScript && node.Kind == dialect.KindAtomic:
+		return printed{}, p.unsupported(node.Kind.String())
+	case node.Kind == dialect.KindAtomic:
+		prefix = "(?>"
+	case node.Kind == dialect.KindLookAhead && node.Negated:
+		prefix = "(?!"
+	case node.Kind == dialect.KindLookAhead:
+		prefix = "(?="
+	case node.Negated:
+		prefix = "(?<!"
+	default:
+		prefix = "(?<="
+	}
+
+	parentFlags := p.flags
+	defer func() { p.flags = parentFlags }()
+
+	sub, err := p.printConcat(node.Sub)
+	if err != nil {
+		return printed{}, err
+	}
+
+	return atom(prefix + sub.text + ")"), nil
+}
+
+func (p *printer) printBackRef(node *dialect.AST) (printed, error) {
+	switch {
+	case p.dialect == dialect.POSIXExtended:
+		return printed{}, p.unsupported(node.Kind.String())
+	case node.Name != "":
+		return atom(`\k<` + node.Name + `>`), nil
+	case p.dialect == dialect.PCRE:
+		return atom(fmt.Sprintf(`\g{%d}`, node.Index)), nil
+	case p.dialect == dialect.DotNET:
+		return atom(fmt.Sprintf(`\k<%d>`, node.Index)), nil
+	default:
+		// The group separates the reference from following digits.
+		return atom(fmt.Sprintf(`(?:\%d)`, node.Index)), nil
+	}
+}
+
+func (p *printer) printRaw(node *dialect.AST) (printed, error) {
+	if matches := rawFlagsRe.FindStringSubmatch(node.Value); matches != nil {
+		p.flags = applySyntaxFlags(p.flags, matches[1])
+
+		return printed{text: "", prec: precConcat}, nil
+	}
+
+	re, err := syntax.Parse(node.Value, p.flags)
+	if err != nil {
+		return printed{}, fmt.Errorf("parsing raw %q: %w", node.Value, err)
+	}
+
+	// Flags are already applied by the parser.
+	parentFlags := p.flags
+	defer func() { p.flags = parentFlags }()
+
+	p.flags = syntax.Perl
+
+	return p.Print(syntaxToAST(re))
+}
+
+func (p *printer) printConcat(nodes []*dialect.AST) (printed, error) {
+	parts := make([]printed, 0, len(nodes))
+
+	for _, node := range nodes {
+		part, err := p.Print(node)
+		if err != nil {
+			return printed{}, err
+		}
+
+		if part.text != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return printed{text: "", prec: precConcat}, nil
+	case 1:
+		return parts[0], nil
+	}
+
+	var sb strings.Builder
+
+	for _, part := range parts {
+		if part.prec < precConcat {
+			part = p.nonCaptured(part.text)
+		}
+
+		_, _ = sb.WriteString(part.text)
+	}
+
+	return printed{text: sb.String(), prec: precConcat}, nil
+}
+
+func (p *printer) printAlternate(nodes []*dialect.AST) (printed, error) {
+	if len(nodes) == 1 {
+		return p.Print(nodes[0])
+	}
+
+	texts := make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		part, err := p.Print(node)
+		if err != nil {
+			return printed{}, err
+		}
+
+		texts = append(texts, part.text)
+	}
+
+	return printed{text: strings.Join(texts, "|"), prec: precAlternate}, nil
+}
+
+func (p *printer) printGroup(node *dialect.AST) (printed, error) {
+	parentFlags := p.flags
+	defer func() { p.flags = parentFlags }()
+
+	p.flags = applySyntaxFlags(p.flags, node.Flags)
+
+	sub, err := p.printConcat(node.Sub)
+	if err != nil {
+		return printed{}, err
+	}
+
+	switch {
+	case !node.Capture:
+		return sub, nil
+	case node.Name == "":
+		return atom("(" + sub.text + ")"), nil
+	case p.dialect == dialect.POSIXExtended:
+		return printed{}, p.unsupported("named groups")
+	default:
+		return atom("(?<" + node.Name + ">" + sub.text + ")"), nil
+	}
+}
+
+func (p *printer) printRepeat(node *dialect.AST) (printed, error) {
+	sub, err := p.printConcat(node.Sub)
+	if err != nil {
+		return printed{}, err
+	}
+
+	if sub.prec < precAtom || sub.text == "" {
+		sub = p.nonCaptured(sub.text)
+	}
+
+	var suffix string
+
+	switch {
+	case node.Min == 0 && node.Max == -1:
+		suffix = "*"
+	case node.Min == 1 && node.Max == -1:
+		suffix = "+"
+	case node.Min == 0 && node.Max == 1:
+		suffix = "?"
+	case node.Max == -1:
+		suffix = fmt.Sprintf("{%d,}", node.Min)
+	case node.Min == node.Max:
+		suffix = fmt.Sprintf("{%d}", node.Min)
+	default:
+		suffix = fmt.Sprintf("{%d,%d}", node.Min, node.Max)
+	}
+
+	if node.PreferFewer != (p.flags&syntax.NonGreedy != 0) {
+		if p.dialect == dialect.POSIXExtended {
+			return printed{}, p.unsupported("non-greedy repetitions")
+		}
+
+		suffix += "?"
+	}
+
+	return printed{text: sub.text + suffix, prec: precRepeat}, nil
+}
+
+func (p *printer) nonCaptured(text string) printed {
+	if p.dialect == dialect.POSIXExtended {
+		// POSIX ERE doesn't have non-captured groups.
+		return atom("(" + text + ")")
+	}
+
+	return atom("(?:" + text + ")")
+}
+
+func (p *printer) printLiteral(value string) printed {
+	runes := []rune(value)
+
+	var sb strings.Builder
+
+	for _, r := range runes {
+		if p.flags&syntax.FoldCase != 0 {
+			if folded := charclass.FoldCase([]rune{r, r}); len(folded) > 2 || folded[0] != folded[1] {
+				_, _ = sb.WriteString(p.bracket(folded, false))
+
+				continue
+			}
+		}
+
+		_, _ = sb.WriteString(p.escape(r))
+	}
+
+	switch len(runes) {
+	case 0:
+		return printed{text: "", prec: precConcat}
+	case 1:
+		return atom(sb.String())
+	default:
+		return printed{text: sb.String(), prec: precConcat}
+	}
+}
+
+func (p *printer) printClass(node *dialect.AST) (printed, error) {
+	if node.Value != "" && p.flags&syntax.FoldCase == 0 {
+		if item, ok := p.className(node.Value); ok {
+			if !node.Negated && !strings.HasPrefix(item, "[") {
+				return atom(item), nil
+			}
+
+			switch {
+			case node.Negated && (item == `\d` || item == `\w`):
+				return atom(strings.ToUpper(item)), nil
+			case node.Negated && strings.HasPrefix(item, `\p{`):
+				return atom(`\P{` + strings.TrimPrefix(item, `\p{`)), nil
+			case node.Negated:
+				return atom("[^" + item + "]"), nil
+			}
+
+			return atom("[" + item + "]"), nil
+		}
+	}
+
+	ranges := node.Ranges
+	if p.flags&syntax.FoldCase != 0 {
+		ranges = charclass.FoldCase(ranges)
+	}
+
+	negated := node.Negated
+
+	if !negated && len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		negated = true
+		ranges = charclass.Negate(ranges)
+	}
+
+	switch {
+	case negated && len(ranges) == 0:
+		return p.printAnyChar(true), nil
+	case len(ranges) == 0:
+		if p.dialect == dialect.POSIXExtended {
+			return printed{}, p.unsupported("empty classes")
+		}
+
+		return atom("(?!)"), nil
+	case !negated && len(ranges) == 2 && ranges[0] == ranges[1]:
+		return atom(p.escape(ranges[0])), nil
+	}
+
+	if p.dialect == dialect.DotNET && ranges[len(ranges)-1] > unicode.MaxRune&0xFFFF {
+		return printed{}, p.unsupported("characters above U+FFFF in classes")
+	}
+
+	return atom(p.bracket(ranges, negated)), nil
+}
+
+// className returns the class by its name, as it is written inside
+// brackets. It returns false, if the dialect doesn't have the same class.
+func (p *printer) className(value string) (string, bool) {
+	switch {
+	case value == `\d` || value == `\w`:
+		// `\s` of other dialects contains more characters.
+		return value, p.dialect == dialect.PCRE || p.dialect == dialect.ECMAScript
+	case strings.HasPrefix(value, "[:"):
+		name := strings.TrimSuffix(strings.TrimPrefix(value, "[:"), ":]")
+
+		return value, p.dialect == dialect.PCRE ||
+			(p.dialect == dialect.POSIXExtended && posixClassNames[name])
+	case strings.HasPrefix(value, `\p{`):
+		name := strings.TrimSuffix(strings.TrimPrefix(value, `\p{`), "}")
+		_, isCategory := unicode.Categories[name]
+		_, isScript := unicode.Scripts[name]
+
+		switch {
+		case p.dialect == dialect.PCRE && (isCategory || isScript):
+			return value, true
+		case p.dialect == dialect.ECMAScript && isScript:
+			return `\p{Script=` + name + `}`, true
+		case (p.dialect == dialect.ECMAScript || p.dialect == dialect.DotNET) && isCategory:
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// bracket returns a bracket expression with the ranges.
+func (p *printer) bracket(ranges []rune, negated bool) string {
+	if p.dialect == dialect.POSIXExtended {
+		return posixBracket(ranges, negated)
+	}
+
+	var sb strings.Builder
+
+	_ = sb.WriteByte('[')
+
+	if negated {
+		_ = sb.WriteByte('^')
+	}
+
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+
+		_, _ = sb.WriteString(p.classEscape(lo))
+
+		if hi > lo+1 {
+			_ = sb.WriteByte('-')
+		}
+
+		if hi > lo {
+			_, _ = sb.WriteString(p.classEscape(hi))
+		}
+	}
+
+	_ = sb.WriteByte(']')
+
+	return sb.String()
+}
+
+// posixBracket returns a bracket expression of POSIX ERE. It doesn't
+// have escapes, so `]` goes first and `-` goes last.
+func posixBracket(ranges []rune, negated bool) string {
+	var (
+		items                                  strings.Builder
+		hasBracket, hasDash, hasCaret, hasOpen bool
+	)
+
+	special := func(r rune) bool {
+		switch r {
+		case ']':
+			hasBracket = true
+		case '-':
+			hasDash = true
+		case '^':
+			hasCaret = true
+		case '[':
+			hasOpen = true
+		default:
+			return false
+		}
+
+		return true
+	}
+
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+
+		// Special characters are written separately.
+		for lo <= hi && special(lo) {
+			lo++
+		}
+
+		for hi >= lo && special(hi) {
+			hi--
+		}
+
+		switch {
+		case lo > hi:
+		case lo == hi:
+			_, _ = items.WriteString(string(lo))
+		case lo+1 == hi:
+			_, _ = items.WriteString(string(lo) + string(hi))
+		default:
+			_, _ = items.WriteString(string(lo) + "-" + string(hi))
+		}
+	}
+
+	var sb strings.Builder
+
+	_ = sb.WriteByte('[')
+
+	if negated {
+		_ = sb.WriteByte('^')
+	}
+
+	if hasBracket {
+		_ = sb.WriteByte(']')
+	}
+
+	_, _ = sb.WriteString(items.String())
+
+	if hasOpen {
+		_ = sb.WriteByte('[')
+	}
+
+	if hasCaret && !hasBracket && !hasOpen && items.Len() == 0 {
+		// `^` can't be the first, `-` can.
+		if hasDash {
+			_ = sb.WriteByte('-')
+		}
+
+		_ = sb.WriteByte('^')
+
+		return sb.String() + "]"
+	}
+
+	if hasCaret {
+		_ = sb.WriteByte('^')
+	}
+
+	if hasDash {
+		_ = sb.WriteByte('-')
+	}
+
+	return sb.String() + "]"
+}
+
+// escape returns the character escaped outside of brackets.
+func (p *printer) escape(r rune) string {
+	switch p.dialect {
+	case dialect.POSIXExtended:
+		if strings.ContainsRune(`\.[()*+?{|^$`, r) {
+			return `\` + string(r)
+		}
+
+		return string(r)
+	case dialect.ECMAScript:
+		if r == '/' {
+			return `\/`
+		}
+	}
+
+	if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+		return `\` + string(r)
+	}
+
+	if !unicode.IsPrint(r) {
+		return p.hexEscape(r)
+	}
+
+	return string(r)
+}
+
+// classEscape returns the character escaped inside of brackets.
+func (p *printer) classEscape(r rune) string {
+	if strings.ContainsRune(`\]-^[`, r) {
+		return `\` + string(r)
+	}
+
+	if !unicode.IsPrint(r) {
+		return p.hexEscape(r)
+	}
+
+	return string(r)
+}
+
+// controlEscapes are escapes, that are the same in all dialects
+// except POSIX ERE.
+var controlEscapes = map[rune]string{
+	'\t': `\t`, '\n': `\n`, '\v': `\v`, '\f': `\f`, '\r': `\r`,
+}
+
+func (p *printer) hexEscape(r rune) string {
+	if escaped, ok := controlEscapes[r]; ok {
+		return escaped
+	}
+
+	switch {
+	case p.dialect == dialect.PCRE:
+		return fmt.Sprintf(`\x{%X}`, r)
+	case r <= 0xFFFF:
+		return fmt.Sprintf(`\u%04X`, r)
+	case p.dialect == dialect.ECMAScript:
+		return fmt.Sprintf(`\u{%X}`, r)
+	default:
+		r1, r2 := utf16.EncodeRune(r)
+
+		return fmt.Sprintf(`\u%04X\u%04X`, r1, r2)
+	}
+}
+
+func (p *printer) printAnyChar(withNewLine bool) printed {
+	switch {
+	case p.dialect == dialect.POSIXExtended && withNewLine:
+		return atom(".")
+	case p.dialect == dialect.POSIXExtended:
+		return atom("[^\n]")
+	case withNewLine && p.dialect == dialect.ECMAScript:
+		return atom(`[\s\S]`)
+	case withNewLine:
+		return atom(`(?s:.)`)
+	default:
+		return atom(`[^\n]`)
+	}
+}
+
+func (p *printer) printBegin(line bool) (printed, error) {
+	switch {
+	case !line && (p.dialect == dialect.ECMAScript || p.dialect == dialect.POSIXExtended):
+		return atom("^"), nil
+	case !line:
+		return atom(`\A`), nil
+	case p.dialect == dialect.ECMAScript:
+		return atom(`(?<![^\n])`), nil
+	case p.dialect == dialect.POSIXExtended:
+		return printed{}, p.unsupported("begin of a line")
+	default:
+		return atom(`(?m:^)`), nil
+	}
+}
+
+func (p *printer) printEnd(line bool) (printed, error) {
+	switch {
+	case !line && (p.dialect == dialect.ECMAScript || p.dialect == dialect.POSIXExtended):
+		return atom("$"), nil
+	case !line:
+		return atom(`\z`), nil
+	case p.dialect == dialect.ECMAScript:
+		return atom(`(?![^\n])`), nil
+	case p.dialect == dialect.POSIXExtended:
+		return printed{}, p.unsupported("end of a line")
+	default:
+		return atom(`(?m:$)`), nil
+	}
+}
+
+func (p *printer) printWordBoundary(negated bool) (printed, error) {
+	switch {
+	case p.dialect == dialect.POSIXExtended:
+		return printed{}, p.unsupported("word boundaries")
+	case p.dialect == dialect.DotNET && negated:
+		// `\B` of .NET is not limited by ASCII.
+		return atom(`(?:(?<=` + wordClass + `)(?=` + wordClass + `)|(?<!` + wordClass + `)(?!` + wordClass + `))`), nil
+	case p.dialect == dialect.DotNET:
+		return atom(`(?:(?<=` + wordClass + `)(?!` + wordClass + `)|(?<!` + wordClass + `)(?=` + wordClass + `))`), nil
+	case negated:
+		return atom(`\B`), nil
+	default:
+		return atom(`\b`), nil
+	}
+}
+
+// syntaxToAST converts the parsed expression to the tree. Flags of the
+// expression are expressed by groups, the tree doesn't depend on flags
+// of the parent.
+//
+// nolint: cyclop // One case per an operation.
+func syntaxToAST(re *syntax.Regexp) *dialect.AST {
+	withFlags := func(flags string, node *dialect.AST) *dialect.AST {
+		group := dialect.NewAST(dialect.KindGroup, node)
+		group.Flags = flags
+
+		return group
+	}
+
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return dialect.NewAST(dialect.KindClass)
+	case syntax.OpLiteral:
+		node := dialect.NewAST(dialect.KindLiteral)
+		node.Value = string(re.Rune)
+
+		if re.Flags&syntax.FoldCase != 0 {
+			return withFlags("i", node)
+		}
+
+		return node
+	case syntax.OpCharClass:
+		node := dialect.NewAST(dialect.KindClass)
+		node.Ranges = append([]rune(nil), re.Rune...)
+
+		// Named unicode classes are kept, so `\pL` isn't expanded
+		// in dialects with `\p{L}`.
+		if name := unicodeClassName(node.Ranges); name != "" {
+			node.Value = name
+		} else if negated := charclass.Negate(node.Ranges); len(negated) > 0 {
+			if name := unicodeClassName(negated); name != "" {
+				node.Value = name
+				node.Ranges = negated
+				node.Negated = true
+			}
+		}
+
+		return node
+	case syntax.OpAnyCharNotNL:
+		return dialect.NewAST(dialect.KindAnyChar)
+	case syntax.OpAnyChar:
+		return withFlags("s", dialect.NewAST(dialect.KindAnyChar))
+	case syntax.OpBeginLine:
+		return withFlags("m", dialect.NewAST(dialect.KindBegin))
+	case syntax.OpEndLine:
+		return withFlags("m", dialect.NewAST(dialect.KindEnd))
+	case syntax.OpBeginText:
+		return dialect.NewAST(dialect.KindBeginOfText)
+	case syntax.OpEndText:
+		return dialect.NewAST(dialect.KindEndOfText)
+	case syntax.OpWordBoundary:
+		return dialect.NewAST(dialect.KindWordBoundary)
+	case syntax.OpNoWordBoundary:
+		return dialect.NewAST(dialect.KindNoWordBoundary)
+	case syntax.OpCapture:
+		node := dialect.NewAST(dialect.KindGroup, syntaxToAST(re.Sub[0]))
+		node.Capture = true
+		node.Name = re.Name
+
+		return node
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		node := dialect.NewAST(dialect.KindRepeat, syntaxToAST(re.Sub[0]))
+		node.PreferFewer = re.Flags&syntax.NonGreedy != 0
+
+		switch re.Op {
+		case syntax.OpStar:
+			node.Min, node.Max = 0, -1
+		case syntax.OpPlus:
+			node.Min, node.Max = 1, -1
+		case syntax.OpQuest:
+			node.Min, node.Max = 0, 1
+		default:
+			node.Min, node.Max = re.Min, re.Max
+		}
+
+		return node
+	case syntax.OpConcat, syntax.OpAlternate:
+		kind := dialect.KindConcat
+		if re.Op == syntax.OpAlternate {
+			kind = dialect.KindAlternate
+		}
+
+		sub := make([]*dialect.AST, 0, len(re.Sub))
+		for _, s := range re.Sub {
+			sub = append(sub, syntaxToAST(s))
+		}
+
+		return dialect.NewAST(kind, sub...)
+	default:
+		return dialect.NewAST(dialect.KindEmpty)
+	}
+}
+
+// unicodeClasses are unicode categories and scripts of raw expressions.
+// They are computed on the first use.
+var unicodeClasses struct {
+	once    sync.Once
+	names   []string
+	classes [][]rune
+}
+
+// unicodeClassName returns `\p{Name}` of the unicode category or script
+// with the same ranges. It returns an empty string if there is no such class.
+func unicodeClassName(ranges []rune) string {
+	unicodeClasses.once.Do(func() {
+		for _, tables := range []map[string]*unicode.RangeTable{unicode.Categories, unicode.Scripts} {
+			names := make([]string, 0, len(tables))
+			for name := range tables {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				unicodeClasses.names = append(unicodeClasses.names, name)
+				unicodeClasses.classes = append(unicodeClasses.classes, charclass.FromTable(tables[name]))
+			}
+		}
+	})
+
+	for i, class := range unicodeClasses.classes {
+		if reflect.DeepEqual(ranges, class) {
+			return `\p{` + unicodeClasses.names[i] + `}`
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,360 @@
+package rex_test
+
+import (
+	"errors"
+	"testing"
+	"unicode"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+func TestRegExpStringFor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Chain    []dialect.Token
+		Expected map[dialect.Dialect]string
+	}{{
+		Name: "named_group",
+		Chain: []dialect.Token{
+			rex.Chars.Begin(),
+			rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("number"),
+			rex.Chars.End(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.RE2:        `^(?P<number>\d+)$`,
+			dialect.PCRE:       `\A(?<number>\d+)\z`,
+			dialect.ECMAScript: `^(?<number>\d+)$`,
+			dialect.DotNET:     `\A(?<number>[0-9]+)\z`,
+		},
+	}, {
+		Name: "captured_group",
+		Chain: []dialect.Token{
+			rex.Group.Define(rex.Chars.Single('a')).Repeat().ZeroOrOne(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:          `(a)?`,
+			dialect.ECMAScript:    `(a)?`,
+			dialect.POSIXExtended: `(a)?`,
+			dialect.DotNET:        `(a)?`,
+		},
+	}, {
+		Name: "literal",
+		Chain: []dialect.Token{
+			rex.Common.Text("a.b/c^"),
+			rex.Chars.Single('\n'),
+			rex.Chars.Single(0x1),
+			rex.Chars.Single(0x10FFFE),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:          `a\.b/c\^\n\x{1}\x{10FFFE}`,
+			dialect.ECMAScript:    `a\.b\/c\^\n\u0001\u{10FFFE}`,
+			dialect.POSIXExtended: "a\\.b/c\\^\n\x01\U0010FFFE",
+			dialect.DotNET:        `a\.b/c\^\n\u0001\uDBFF\uDFFE`,
+		},
+	}, {
+		Name: "class",
+		Chain: []dialect.Token{
+			rex.Common.Class(
+				rex.Chars.Range('a', 'f'),
+				rex.Chars.Single('-'),
+				rex.Chars.Single(']'),
+				rex.Chars.Single('^'),
+			),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:          `[\-\]\^a-f]`,
+			dialect.ECMAScript:    `[\-\]\^a-f]`,
+			dialect.POSIXExtended: `[]a-f^-]`,
+			dialect.DotNET:        `[\-\]\^a-f]`,
+		},
+	}, {
+		Name: "class_posix_only_caret_and_dash",
+		Chain: []dialect.Token{
+			rex.Common.Class(rex.Chars.Single('-'), rex.Chars.Single('^')),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.POSIXExtended: `[-^]`,
+		},
+	}, {
+		Name: "named_classes",
+		Chain: []dialect.Token{
+			rex.Chars.WordCharacter(),
+			rex.Chars.Whitespace(),
+			rex.Chars.Alphabetic(),
+			rex.Chars.UnicodeByName("Lu"),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `\w[\t\n\f\r ][[:alpha:]]\p{Lu}`,
+			dialect.ECMAScript: `\w[\t\n\f\r ][A-Za-z]\p{Lu}`,
+		},
+	}, {
+		Name: "unicode_script",
+		Chain: []dialect.Token{
+			rex.Chars.Unicode(unicode.Greek),
+			rex.Common.NotClass(rex.Chars.Alphabetic()),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `\p{Greek}[^A-Za-z]`,
+			dialect.ECMAScript: `\p{Script=Greek}[^A-Za-z]`,
+		},
+	}, {
+		Name: "negated_classes",
+		Chain: []dialect.Token{
+			rex.Chars.Digits().Not(),
+			rex.Chars.Unicode(unicode.Greek).Not(),
+			rex.Chars.Alphabetic().Not(),
+			rex.Chars.Whitespace().Not(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.RE2:        `\D\P{Greek}[[:^alpha:]]\S`,
+			dialect.PCRE:       `\D\P{Greek}[^[:alpha:]][^\t\n\f\r ]`,
+			dialect.ECMAScript: `\D\P{Script=Greek}[^A-Za-z][^\t\n\f\r ]`,
+		},
+	}, {
+		Name: "posix_class",
+		Chain: []dialect.Token{
+			rex.Common.NotClass(rex.Chars.Punctuation(), rex.Chars.Digits()),
+			rex.Chars.Upper(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.POSIXExtended: `[^!-@\-` + "`" + `{-~[][[:upper:]]`,
+		},
+	}, {
+		Name: "flags",
+		Chain: []dialect.Token{
+			rex.Flags.Combine(rex.Flags.CaseInsensitive(), rex.Flags.Multiline()),
+			rex.Chars.Begin(),
+			rex.Common.Text("a1"),
+			rex.Flags.CaseInsensitive().Disable().Group(rex.Chars.Single('b')),
+			rex.Chars.End(),
+			rex.Chars.EndOfText(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `(?m:^)[Aa]1b(?m:$)\z`,
+			dialect.ECMAScript: `(?<![^\n])[Aa]1b(?![^\n])$`,
+			dialect.DotNET:     `(?m:^)[Aa]1b(?m:$)\z`,
+		},
+	}, {
+		Name: "any",
+		Chain: []dialect.Token{
+			rex.Chars.Any(),
+			rex.Flags.AnyIncludeNewLine(),
+			rex.Chars.Any(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:          `[^\n](?s:.)`,
+			dialect.ECMAScript:    `[^\n][\s\S]`,
+			dialect.POSIXExtended: "[^\n].",
+			dialect.DotNET:        `[^\n](?s:.)`,
+		},
+	}, {
+		Name: "repetitions",
+		Chain: []dialect.Token{
+			rex.Group.NonCaptured(rex.Common.Text("ab")).Repeat().Between(2, 3),
+			rex.Chars.Digits().Repeat().EqualOrMoreThan(2),
+			rex.Flags.Ungreedy(),
+			rex.Chars.Single('c').Repeat().OneOrMore(),
+			rex.Chars.Single('d').Repeat().OneOrMorePreferFewer(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `(?:ab){2,3}\d{2,}c+?d+`,
+			dialect.ECMAScript: `(?:ab){2,3}\d{2,}c+?d+`,
+		},
+	}, {
+		Name: "raw",
+		Chain: []dialect.Token{
+			rex.Common.Raw(`ab|cd`),
+			rex.Common.Raw(`(?i)`),
+			rex.Common.Raw(`c(?P<name>d)\x{41}*?`),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `(?:ab|cd)[Cc](?<name>[Dd])[Aa]*?`,
+			dialect.ECMAScript: `(?:ab|cd)[Cc](?<name>[Dd])[Aa]*?`,
+		},
+	}, {
+		Name: "raw_posix",
+		Chain: []dialect.Token{
+			rex.Common.Raw(`(?:ab|cd)+[ab]`),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.POSIXExtended: `(ab|cd)+[ab]`,
+		},
+	}, {
+		Name: "raw_unicode_classes",
+		Chain: []dialect.Token{
+			rex.Common.Raw(`\pL+[^\pL]\p{Greek}`),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `\p{L}+\P{L}\p{Greek}`,
+			dialect.ECMAScript: `\p{L}+\P{L}\p{Script=Greek}`,
+		},
+	}, {
+		Name: "word_boundary",
+		Chain: []dialect.Token{
+			rex.Chars.ASCIIWordBoundary(),
+			rex.Chars.NotASCIIWordBoundary(),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `\b\B`,
+			dialect.ECMAScript: `\b\B`,
+			dialect.DotNET: `(?:(?<=[0-9A-Za-z_])(?![0-9A-Za-z_])|(?<![0-9A-Za-z_])(?=[0-9A-Za-z_]))` +
+				`(?:(?<=[0-9A-Za-z_])(?=[0-9A-Za-z_])|(?<![0-9A-Za-z_])(?![0-9A-Za-z_]))`,
+		},
+	}, {
+		Name: "composite",
+		Chain: []dialect.Token{
+			rex.Group.NonCaptured(rex.Group.Composite(
+				rex.Common.Text("ab"),
+				rex.Common.Text("cd"),
+			).NonCaptured()).Repeat().ZeroOrMore(),
+			rex.Chars.Single('e'),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:          `(?:ab|cd)*e`,
+			dialect.POSIXExtended: `(ab|cd)*e`,
+		},
+	}, {
+		Name: "lookaround",
+		Chain: []dialect.Token{
+			rex.Group.LookBehind(rex.Chars.Single('a')),
+			rex.Group.NegativeLookBehind(rex.Chars.Single('b')),
+			rex.Flags.CaseInsensitive().Group(rex.Group.LookAhead(rex.Chars.Single('c'))),
+			rex.Group.NegativeLookAhead(rex.Chars.Single('d')),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `(?<=a)(?<!b)(?=[Cc])(?!d)`,
+			dialect.ECMAScript: `(?<=a)(?<!b)(?=[Cc])(?!d)`,
+			dialect.DotNET:     `(?<=a)(?<!b)(?=[Cc])(?!d)`,
+		},
+	}, {
+		Name: "atomic",
+		Chain: []dialect.Token{
+			rex.Group.Atomic(rex.Chars.Digits().Repeat().OneOrMore()),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:   `(?>\d+)`,
+			dialect.DotNET: `(?>[0-9]+)`,
+		},
+	}, {
+		Name: "back_references",
+		Chain: []dialect.Token{
+			rex.Group.Define(rex.Common.Class(rex.Chars.Runes(`'"`))).WithName("quote"),
+			rex.Group.Define(rex.Chars.Digits()),
+			rex.Common.BackRef("quote"),
+			rex.Common.BackRef("2"),
+			rex.Chars.Single('1'),
+		},
+		Expected: map[dialect.Dialect]string{
+			dialect.PCRE:       `(?<quote>["'])(\d)\k<quote>\g{2}1`,
+			dialect.ECMAScript: `(?<quote>["'])(\d)\k<quote>(?:\2)1`,
+			dialect.DotNET:     `(?<quote>["'])([0-9])\k<quote>\k<2>1`,
+		},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			re := rex.New(tc.Chain...)
+
+			for d, expected := range tc.Expected {
+				actual, err := re.StringFor(d)
+				if err != nil {
+					t.Fatalf("%s: %v", d, err)
+				}
+
+				if actual != expected {
+					t.Fatalf("%s: Actual: %#q, Expected: %#q", d, actual, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRegExpStringForErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name    string
+		Chain   []dialect.Token
+		Dialect dialect.Dialect
+	}{{
+		Name:    "unknown_dialect",
+		Chain:   []dialect.Token{rex.Chars.Single('a')},
+		Dialect: "Unknown",
+	}, {
+		Name: "posix_named_group",
+		Chain: []dialect.Token{
+			rex.Group.Define(rex.Chars.Single('a')).WithName("name"),
+		},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "posix_non_greedy",
+		Chain:   []dialect.Token{rex.Chars.Single('a').Repeat().ZeroOrMorePreferFewer()},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "posix_multiline",
+		Chain:   []dialect.Token{rex.Flags.Multiline(), rex.Chars.Begin()},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "posix_word_boundary",
+		Chain:   []dialect.Token{rex.Chars.ASCIIWordBoundary()},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "dotnet_astral_class",
+		Chain:   []dialect.Token{rex.Chars.Range('😀', '😂')},
+		Dialect: dialect.DotNET,
+	}, {
+		Name:    "ecmascript_atomic",
+		Chain:   []dialect.Token{rex.Group.Atomic(rex.Chars.Single('a'))},
+		Dialect: dialect.ECMAScript,
+	}, {
+		Name:    "posix_look_ahead",
+		Chain:   []dialect.Token{rex.Group.LookAhead(rex.Chars.Single('a'))},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "posix_back_reference",
+		Chain:   []dialect.Token{rex.Common.BackRef("1")},
+		Dialect: dialect.POSIXExtended,
+	}, {
+		Name:    "re2_look_ahead",
+		Chain:   []dialect.Token{rex.Chars.Single('a'), rex.Group.LookAhead(rex.Chars.Single('b'))},
+		Dialect: dialect.RE2,
+	}, {
+		Name:    "re2_atomic",
+		Chain:   []dialect.Token{rex.Group.Atomic(rex.Chars.Single('a'))},
+		Dialect: dialect.RE2,
+	}, {
+		Name:    "re2_back_reference",
+		Chain:   []dialect.Token{rex.Common.BackRef("q")},
+		Dialect: dialect.RE2,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := rex.New(tc.Chain...).StringFor(tc.Dialect)
+			if !errors.Is(err, rex.ErrUnsupportedSyntax) {
+				t.Fatalf("Actual: %v, Expected: %v", err, rex.ErrUnsupportedSyntax)
+			}
+		})
+	}
+
+	t.Run("token_error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rex.New(rex.Chars.Range('z', 'a')).StringFor(dialect.PCRE)
+		if !errors.Is(err, base.ErrInvalidRange) {
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidRange)
+		}
+	})
+}
@@ -3,28 +3,102 @@ package rex
 import (
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/hedhyw/rex/internal/helper"
 	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
 )
 
 // RegExp helps to build regular expressions.
 // Use rex.New() for creating.
 type RegExp struct {
-	expr *strings.Builder
+	tokens   []dialect.Token
+	expr     *strings.Builder
+	err      error
+	compiled *compiledRegExp
+
+	uniqueGroupNames bool
+}
+
+// compiledRegExp caches the compiled regular expression for matching
+// methods.
+type compiledRegExp struct {
+	once sync.Once
+	re   *regexp.Regexp
+	err  error
 }
 
 // New creates a new RegExp from tokens.
+//
+// Tokens can report an invalid input, use RegExp.Err to check it.
+// Compile and MustCompile also take these errors into account.
+// Names of groups should be unique, otherwise base.ErrInvalidGroupName
+// is reported, see also RegExp.WithUniqueGroupNames.
 func New(tokens ...dialect.Token) *RegExp {
+	return newRegExp(tokens, false)
+}
+
+func newRegExp(tokens []dialect.Token, uniqueGroupNames bool) *RegExp {
+	// Alternations should not leak into the concatenation: `(?:a|b)c`.
+	tokens = helper.GroupAlternations(tokens, nonCaptured)
+
 	b := &RegExp{
-		expr: &strings.Builder{},
+		tokens:   tokens,
+		expr:     &strings.Builder{},
+		err:      nil,
+		compiled: &compiledRegExp{once: sync.Once{}, re: nil, err: nil},
+
+		uniqueGroupNames: uniqueGroupNames,
 	}
 
-	_, _ = helper.ProcessTokens(b.expr, tokens)
+	_, b.err = helper.ProcessTokens(newGroupNames(b.expr, uniqueGroupNames), tokens)
 
 	return b
 }
 
+// WithUniqueGroupNames rebuilds the regular expression, renaming
+// duplicated group names instead of reporting an error. The second
+// group `name` becomes `name_2`, the third one `name_3` and so on.
+// It is useful when the same sub-expression is used more than once.
+//
+// Example usage:
+//
+//	octet := rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("octet")
+//
+//	rex.New(octet, rex.Chars.Single('.'), octet).WithUniqueGroupNames()
+//	// (?P<octet>\d+)\.(?P<octet_2>\d+)
+func (r RegExp) WithUniqueGroupNames() *RegExp {
+	return newRegExp(r.tokens, true)
+}
+
+// NewE is like New but also returns all errors reported by tokens.
+func NewE(tokens ...dialect.Token) (*RegExp, error) {
+	b := New(tokens...)
+
+	return b, b.Err()
+}
+
+// Err returns all errors reported by tokens while building
+// the regular expression. It returns nil if there are no errors.
+//
+// Use errors.Is to check a specific error.
+func (r RegExp) Err() error {
+	return r.err
+}
+
+// AST returns a structured tree of the regular expression. Tokens that
+// don't implement dialect.Node are represented as dialect.KindRaw.
+func (r RegExp) AST() *dialect.AST {
+	node := dialect.ConcatAST(r.tokens...)
+
+	if r.uniqueGroupNames {
+		renameGroups(node)
+	}
+
+	return node
+}
+
 // String returns a text of the regular expression.
 // It can be called multiple times.
 // It implements fmt.Stringer interface.
@@ -34,7 +108,18 @@ func (r RegExp) String() string {
 
 // Compile parses a regular expression and returns, if successful,
 // a Regexp object that can be used to match against text.
+//
+// It returns errors reported by tokens first. Tokens, that are not
+// supported by RE2, like Group.LookAhead, are reported as ErrUnsupportedSyntax.
 func (r RegExp) Compile() (*regexp.Regexp, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if err := checkRE2(r.AST()); err != nil {
+		return nil, err
+	}
+
 	re, err := regexp.Compile(r.String())
 	if err != nil {
 		return nil, err
@@ -47,5 +132,38 @@ func (r RegExp) Compile() (*regexp.Regexp, error) {
 // It simplifies safe initialization of global variables holding compiled regular
 // expressions.
 func (r RegExp) MustCompile() *regexp.Regexp {
+	if r.err != nil {
+		panic("rex: New(`" + r.String() + "`): " + r.err.Error())
+	}
+
+	if err := checkRE2(r.AST()); err != nil {
+		panic("rex: Compile(`" + r.String() + "`): " + err.Error())
+	}
+
 	return regexp.MustCompile(r.String())
 }
+
+// cachedCompile compiles the regular expression once and reuses
+// the result.
+func (r RegExp) cachedCompile() (*regexp.Regexp, error) {
+	r.compiled.once.Do(func() {
+		r.compiled.re, r.compiled.err = r.Compile()
+	})
+
+	return r.compiled.re, r.compiled.err
+}
+
+// mustCachedCompile is like cachedCompile, but panics like MustCompile.
+func (r RegExp) mustCachedCompile() *regexp.Regexp {
+	re, err := r.cachedCompile()
+	if err != nil {
+		panic("rex: Compile(`" + r.String() + "`): " + err.Error())
+	}
+
+	return re
+}
+
+// nonCaptured wraps the token in a non-captured group.
+func nonCaptured(token dialect.Token) dialect.Token {
+	return base.Group.NonCaptured(token)
+}
@@ -1,10 +1,13 @@
 package rex_test
 
 import (
+	"errors"
+	"regexp/syntax"
 	"testing"
 
 	"github.com/hedhyw/rex/internal/test"
 	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
 	"github.com/hedhyw/rex/pkg/rex"
 )
 
@@ -60,7 +63,49 @@ func TestRexGeneral(t *testing.T) {
 
 			rex.Chars.End(),
 		},
-		Expected: `^[a-zA-Z\d]+@[a-zA-Z\d]+\.[a-zA-Z\d]{2,3}$`,
+		Expected: `^[[:alnum:]]+@[[:alnum:]]+\.[[:alnum:]]{2,3}$`,
+	}}.Run(t)
+}
+
+func TestRexAlternationPrecedence(t *testing.T) {
+	test.RexTestCasesSlice{{
+		Name:     "raw_single",
+		Chain:    []dialect.Token{rex.Common.Raw(`a|b`)},
+		Expected: `a|b`,
+	}, {
+		Name:     "raw_concat",
+		Chain:    []dialect.Token{rex.Chars.Begin(), rex.Common.Raw(`a|b`), rex.Chars.End()},
+		Expected: `^(?:a|b)$`,
+	}, {
+		Name:     "raw_grouped",
+		Chain:    []dialect.Token{rex.Chars.Begin(), rex.Common.Raw(`(a|b)`), rex.Chars.End()},
+		Expected: `^(a|b)$`,
+	}, {
+		Name:     "group",
+		Chain:    []dialect.Token{rex.Group.Define(rex.Common.Raw(`a|b`), rex.Common.Text("c"))},
+		Expected: `((?:a|b)c)`,
+	}, {
+		Name:     "flags_group",
+		Chain:    []dialect.Token{rex.Flags.CaseInsensitive().Group(rex.Common.Raw(`a|b`), rex.Common.Text("c"))},
+		Expected: `(?i:(?:a|b)c)`,
+	}, {
+		Name:     "fragment",
+		Chain:    []dialect.Token{rex.Fragment(rex.Common.Raw(`a|b`), rex.Common.Text("c"))},
+		Expected: `(?:a|b)c`,
+	}, {
+		Name:     "fragment_concat",
+		Chain:    []dialect.Token{rex.Fragment(rex.Common.Raw(`a|b`)), rex.Common.Text("c")},
+		Expected: `(?:a|b)c`,
+	}, {
+		Name:     "raw_split_group",
+		Chain:    []dialect.Token{rex.Common.Raw("("), rex.Chars.Digits(), rex.Common.Raw(")")},
+		Expected: `(\d)`,
+	}, {
+		Name: "raw_split_alternation",
+		Chain: []dialect.Token{
+			rex.Common.Raw("(?:a|"), rex.Chars.Digits(), rex.Common.Raw("|b)"), rex.Common.Text("c"),
+		},
+		Expected: `(?:a|\d|b)c`,
 	}}.Run(t)
 }
 
@@ -102,6 +147,53 @@ func TestRexCompile(t *testing.T) {
 			t.Fatal(err)
 		}
 	})
+
+	t.Run("failed_token", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rex.New(rex.Chars.Range('z', 'a')).Compile()
+		if !errors.Is(err, base.ErrInvalidRange) {
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidRange)
+		}
+	})
+}
+
+func TestRexErr(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+
+		re, err := rex.NewE(rex.Chars.Digits())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if re.Err() != nil {
+			t.Fatal(re.Err())
+		}
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		t.Parallel()
+
+		re, err := rex.NewE(
+			rex.Chars.Range('z', 'a'),
+			rex.Chars.Digits().Repeat().Between(2, 1),
+			rex.Group.Define(rex.Chars.Digits()).WithName(""),
+		)
+
+		switch {
+		case !errors.Is(err, base.ErrInvalidRange):
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidRange)
+		case !errors.Is(err, base.ErrInvalidRepetition):
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidRepetition)
+		case !errors.Is(err, base.ErrInvalidGroupName):
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidGroupName)
+		case !errors.Is(re.Err(), base.ErrInvalidRange):
+			t.Fatalf("Actual: %v, Expected: %v", re.Err(), base.ErrInvalidRange)
+		}
+	})
 }
 
 func TestRexMustCompile(t *testing.T) {
@@ -135,4 +227,136 @@ func TestRexMustCompile(t *testing.T) {
 			t.Fatal("Expected panic")
 		}
 	})
+
+	t.Run("failed_token", func(t *testing.T) {
+		t.Parallel()
+
+		var recovered interface{}
+
+		func() {
+			defer func() { recovered = recover() }()
+
+			_ = rex.New(rex.Chars.Range('z', 'a')).MustCompile()
+		}()
+
+		if recovered == nil {
+			t.Fatal("Expected panic")
+		}
+	})
+}
+
+func TestRexAST(t *testing.T) {
+	t.Parallel()
+
+	node := rex.New(
+		rex.Chars.Begin(),
+		rex.Group.Define(
+			rex.Chars.Digits().Repeat().OneOrMore(),
+		).WithName("number"),
+		rex.Common.Raw(`$`),
+	).AST()
+
+	const expected = `Concat(Begin, Group<number>(Repeat{1,-1}(Class(\d)[0-9])), Raw("$"))`
+
+	if actual := test.FormatAST(node); actual != expected {
+		t.Fatalf("Actual: %s, Expected: %s", actual, expected)
+	}
+
+	var names []string
+
+	node.Walk(func(node *dialect.AST) bool {
+		if node.Kind == dialect.KindGroup && node.Name != "" {
+			names = append(names, node.Name)
+		}
+
+		return true
+	})
+
+	if len(names) != 1 || names[0] != "number" {
+		t.Fatalf("Actual: %q, Expected: %q", names, []string{"number"})
+	}
+}
+
+func TestRexDuplicateGroupNames(t *testing.T) {
+	t.Parallel()
+
+	octet := rex.Group.Define(rex.Chars.Digits().Repeat().OneOrMore()).WithName("octet")
+
+	_, err := rex.NewE(octet, rex.Chars.Single('.'), octet)
+	if !errors.Is(err, base.ErrInvalidGroupName) {
+		t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidGroupName)
+	}
+
+	const expectedErr = `invalid group name: duplicate name "octet" at offset 16, first defined at offset 0`
+	if err.Error() != expectedErr {
+		t.Fatalf("Actual: %q, Expected: %q", err.Error(), expectedErr)
+	}
+
+	re := rex.New(
+		octet, rex.Chars.Single('.'),
+		octet, rex.Chars.Single('.'),
+		rex.Group.Define(octet).WithName("octet_2"),
+	).WithUniqueGroupNames()
+
+	if err := re.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `(?P<octet>\d+)\.(?P<octet_2>\d+)\.(?P<octet_2_2>(?P<octet_3>\d+))`
+	if actual := re.String(); actual != expected {
+		t.Fatalf("Actual: %#q, Expected: %#q", actual, expected)
+	}
+
+	const expectedAST = `Concat(Group<octet>(Repeat{1,-1}(Class(\d)[0-9])), Literal("."), ` +
+		`Group<octet_2>(Repeat{1,-1}(Class(\d)[0-9])), Literal("."), ` +
+		`Group<octet_2_2>(Group<octet_3>(Repeat{1,-1}(Class(\d)[0-9]))))`
+	if actual := test.FormatAST(re.AST()); actual != expectedAST {
+		t.Fatalf("Actual: %s, Expected: %s", actual, expectedAST)
+	}
+
+	match, ok := re.MatchNamed("1.2.3")
+	if !ok || match["octet_3"] != "3" {
+		t.Fatalf("Actual: %v, %v", match, ok)
+	}
+}
+
+func TestRexCompileNotRE2(t *testing.T) {
+	t.Parallel()
+
+	tokens := []dialect.Token{
+		rex.Group.LookAhead(rex.Chars.Single('a')),
+		rex.Group.NegativeLookAhead(rex.Chars.Single('a')),
+		rex.Group.LookBehind(rex.Chars.Single('a')),
+		rex.Group.NegativeLookBehind(rex.Chars.Single('a')),
+		rex.Group.Atomic(rex.Chars.Single('a')),
+		rex.Common.BackRef("1"),
+		rex.Group.NonCaptured(rex.Common.BackRef("name")),
+	}
+
+	for _, token := range tokens {
+		re := rex.New(rex.Group.Define(rex.Chars.Single('a')), token)
+
+		if err := re.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := re.Compile()
+		if !errors.Is(err, rex.ErrUnsupportedSyntax) {
+			t.Fatalf("%s: Actual: %v, Expected: %v", re, err, rex.ErrUnsupportedSyntax)
+		}
+
+		if _, err := re.Syntax(syntax.Perl); !errors.Is(err, rex.ErrUnsupportedSyntax) {
+			t.Fatalf("%s: Actual: %v, Expected: %v", re, err, rex.ErrUnsupportedSyntax)
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%s: Expected panic", re)
+				}
+			}()
+
+			re.MustCompile()
+		}()
+	}
 }
@@ -0,0 +1,349 @@
+package rex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/hedhyw/rex/internal/charclass"
+	"github.com/hedhyw/rex/pkg/dialect"
+)
+
+// maxRepeat is a maximum count of repetitions supported by regexp/syntax.
+const maxRepeat = 1000
+
+// ErrUnsupportedSyntax is returned if a token cannot be converted
+// to regexp/syntax.
+var ErrUnsupportedSyntax = errors.New("unsupported syntax")
+
+// rawFlagsRe matches raw expressions that only set flags: `(?i)`.
+var rawFlagsRe = regexp.MustCompile(`^\(\?([imsU]*(?:-[imsU]*)?)\)$`)
+
+// Syntax builds a regexp/syntax tree straight from tokens, without
+// formatting and parsing the regular expression.
+//
+// Flags have the same meaning as in syntax.Parse, usually they are
+// syntax.Perl or syntax.POSIX. Raw tokens are parsed with these flags.
+//
+// Example usage:
+//
+//	re, err := rex.New(rex.Chars.Digits()).Syntax(syntax.Perl)
+//	if err != nil {
+//		return err
+//	}
+//
+//	prog, err := syntax.Compile(re.Simplify())
+func (r RegExp) Syntax(flags syntax.Flags) (*syntax.Regexp, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	conv := syntaxConverter{
+		flags:       flags,
+		lastGroup:   0,
+		repeatLimit: maxRepeat,
+	}
+
+	return conv.Convert(r.AST())
+}
+
+// syntaxConverter converts dialect.AST to syntax.Regexp. It tracks
+// flags, captured groups and the limit of nested repetitions.
+type syntaxConverter struct {
+	flags       syntax.Flags
+	lastGroup   int
+	repeatLimit int
+}
+
+func newSyntaxRegexp(op syntax.Op, flags syntax.Flags, sub ...*syntax.Regexp) *syntax.Regexp {
+	// nolint: exhaustruct // Other fields are not needed.
+	return &syntax.Regexp{
+		Op:    op,
+		Flags: flags,
+		Sub:   sub,
+	}
+}
+
+// Convert the tree. The method is not safe for concurrent use.
+//
+// nolint: cyclop // One case per a kind.
+func (c *syntaxConverter) Convert(node *dialect.AST) (*syntax.Regexp, error) {
+	switch node.Kind {
+	case dialect.KindEmpty, dialect.KindFlags:
+		if node.Kind == dialect.KindFlags {
+			c.flags = applySyntaxFlags(c.flags, node.Flags)
+		}
+
+		return newSyntaxRegexp(syntax.OpEmptyMatch, c.flags), nil
+	case dialect.KindRaw:
+		return c.convertRaw(node)
+	case dialect.KindLiteral:
+		re := newSyntaxRegexp(syntax.OpLiteral, c.flags&syntax.FoldCase)
+		re.Rune = []rune(node.Value)
+
+		return re, nil
+	case dialect.KindClass:
+		return c.convertClass(node), nil
+	case dialect.KindAnyChar:
+		if c.flags&syntax.DotNL != 0 {
+			return newSyntaxRegexp(syntax.OpAnyChar, c.flags), nil
+		}
+
+		return newSyntaxRegexp(syntax.OpAnyCharNotNL, c.flags), nil
+	case dialect.KindBegin:
+		if c.flags&syntax.OneLine != 0 {
+			return newSyntaxRegexp(syntax.OpBeginText, c.flags), nil
+		}
+
+		return newSyntaxRegexp(syntax.OpBeginLine, c.flags), nil
+	case dialect.KindEnd:
+		if c.flags&syntax.OneLine != 0 {
+			return newSyntaxRegexp(syntax.OpEndText, c.flags|syntax.WasDollar), nil
+		}
+
+		return newSyntaxRegexp(syntax.OpEndLine, c.flags), nil
+	case dialect.KindBeginOfText:
+		return newSyntaxRegexp(syntax.OpBeginText, c.flags), nil
+	case dialect.KindEndOfText:
+		return newSyntaxRegexp(syntax.OpEndText, c.flags), nil
+	case dialect.KindWordBoundary:
+		return newSyntaxRegexp(syntax.OpWordBoundary, c.flags), nil
+	case dialect.KindNoWordBoundary:
+		return newSyntaxRegexp(syntax.OpNoWordBoundary, c.flags), nil
+	case dialect.KindConcat, dialect.KindAlternate:
+		return c.convertList(node)
+	case dialect.KindGroup:
+		return c.convertGroup(node)
+	case dialect.KindRepeat:
+		return c.convertRepeat(node)
+	default:
+		return nil, errNotRE2(node.Kind)
+	}
+}
+
+func errNotRE2(kind dialect.Kind) error {
+	return fmt.Errorf(
+		"%w: %s is not supported by RE2, use RegExp.StringFor with other dialects",
+		ErrUnsupportedSyntax, kind,
+	)
+}
+
+// checkRE2 returns an error if the tree contains nodes, that are not
+// supported by RE2.
+func checkRE2(node *dialect.AST) (err error) {
+	node.Walk(func(node *dialect.AST) bool {
+		switch node.Kind {
+		case dialect.KindLookAhead, dialect.KindLookBehind, dialect.KindAtomic, dialect.KindBackRef:
+			if err == nil {
+				err = errNotRE2(node.Kind)
+			}
+		default:
+		}
+
+		return err == nil
+	})
+
+	return err
+}
+
+func (c *syntaxConverter) convertRaw(node *dialect.AST) (*syntax.Regexp, error) {
+	if matches := rawFlagsRe.FindStringSubmatch(node.Value); matches != nil {
+		c.flags = applySyntaxFlags(c.flags, matches[1])
+
+		return newSyntaxRegexp(syntax.OpEmptyMatch, c.flags), nil
+	}
+
+	re, err := syntax.Parse(node.Value, c.flags)
+	if err != nil {
+		return nil, fmt.Errorf("parsing raw %q: %w", node.Value, err)
+	}
+
+	// Captured groups in raw expressions are numbered from one.
+	maxCap := re.MaxCap()
+	shiftCaptures(re, c.lastGroup)
+	c.lastGroup += maxCap
+
+	return re, nil
+}
+
+func (c *syntaxConverter) convertClass(node *dialect.AST) *syntax.Regexp {
+	ranges := node.Ranges
+
+	if c.flags&syntax.FoldCase != 0 {
+		ranges = charclass.FoldCase(ranges)
+	}
+
+	if node.Negated {
+		if c.flags&syntax.ClassNL == 0 {
+			ranges = charclass.Union(ranges, []rune{'\n', '\n'})
+		}
+
+		ranges = charclass.Negate(ranges)
+	}
+
+	re := newSyntaxRegexp(syntax.OpCharClass, c.flags&syntax.FoldCase)
+	re.Rune = ranges
+
+	return re
+}
+
+func (c *syntaxConverter) convertList(node *dialect.AST) (*syntax.Regexp, error) {
+	op := syntax.OpConcat
+	if node.Kind == dialect.KindAlternate {
+		op = syntax.OpAlternate
+	}
+
+	re := newSyntaxRegexp(op, c.flags)
+
+	for _, sub := range node.Sub {
+		subRe, err := c.Convert(sub)
+		if err != nil {
+			return nil, err
+		}
+
+		if op == syntax.OpConcat && subRe.Op == syntax.OpEmptyMatch {
+			continue
+		}
+
+		re.Sub = append(re.Sub, subRe)
+	}
+
+	switch len(re.Sub) {
+	case 0:
+		return newSyntaxRegexp(syntax.OpEmptyMatch, c.flags), nil
+	case 1:
+		return re.Sub[0], nil
+	default:
+		return re, nil
+	}
+}
+
+func (c *syntaxConverter) convertGroup(node *dialect.AST) (*syntax.Regexp, error) {
+	parentFlags := c.flags
+	defer func() { c.flags = parentFlags }()
+
+	c.flags = applySyntaxFlags(c.flags, node.Flags)
+
+	var re *syntax.Regexp
+
+	if node.Capture {
+		c.lastGroup++
+
+		re = newSyntaxRegexp(syntax.OpCapture, c.flags)
+		re.Cap = c.lastGroup
+		re.Name = node.Name
+	}
+
+	sub, err := c.Convert(dialect.NewAST(dialect.KindConcat, node.Sub...))
+	if err != nil {
+		return nil, err
+	}
+
+	if re == nil {
+		return sub, nil
+	}
+
+	re.Sub = []*syntax.Regexp{sub}
+
+	return re, nil
+}
+
+func (c *syntaxConverter) convertRepeat(node *dialect.AST) (*syntax.Regexp, error) {
+	// Counts of nested repetitions are multiplied like in regexp/syntax.
+	count := node.Max
+	if count < 0 {
+		count = node.Min
+	}
+
+	if node.Min > c.repeatLimit || count > c.repeatLimit {
+		return nil, fmt.Errorf(
+			"%w: repetition {%d,%d} is greater than %d",
+			ErrUnsupportedSyntax, node.Min, node.Max, c.repeatLimit,
+		)
+	}
+
+	flags := c.flags
+	if node.PreferFewer {
+		flags ^= syntax.NonGreedy
+	}
+
+	limit := c.repeatLimit
+	if count > 0 {
+		c.repeatLimit /= count
+	}
+
+	sub, err := c.Convert(dialect.NewAST(dialect.KindConcat, node.Sub...))
+
+	c.repeatLimit = limit
+
+	if err != nil {
+		return nil, err
+	}
+
+	var re *syntax.Regexp
+
+	switch {
+	case node.Min == 0 && node.Max == -1:
+		re = newSyntaxRegexp(syntax.OpStar, flags, sub)
+	case node.Min == 1 && node.Max == -1:
+		re = newSyntaxRegexp(syntax.OpPlus, flags, sub)
+	case node.Min == 0 && node.Max == 1:
+		re = newSyntaxRegexp(syntax.OpQuest, flags, sub)
+	default:
+		re = newSyntaxRegexp(syntax.OpRepeat, flags, sub)
+		re.Min = node.Min
+		re.Max = node.Max
+	}
+
+	return re, nil
+}
+
+func shiftCaptures(re *syntax.Regexp, offset int) {
+	if re.Op == syntax.OpCapture {
+		re.Cap += offset
+	}
+
+	for _, sub := range re.Sub {
+		shiftCaptures(sub, offset)
+	}
+}
+
+// applySyntaxFlags sets and clears flags: `i-m`.
+func applySyntaxFlags(flags syntax.Flags, value string) syntax.Flags {
+	enabled, disabled, _ := strings.Cut(value, "-")
+
+	for _, letter := range enabled {
+		flags = setSyntaxFlag(flags, letter, true)
+	}
+
+	for _, letter := range disabled {
+		flags = setSyntaxFlag(flags, letter, false)
+	}
+
+	return flags
+}
+
+func setSyntaxFlag(flags syntax.Flags, letter rune, enabled bool) syntax.Flags {
+	var flag syntax.Flags
+
+	switch letter {
+	case 'i':
+		flag = syntax.FoldCase
+	case 'm':
+		// Multiline is the opposite of OneLine.
+		enabled = !enabled
+		flag = syntax.OneLine
+	case 's':
+		flag = syntax.DotNL
+	case 'U':
+		flag = syntax.NonGreedy
+	}
+
+	if enabled {
+		return flags | flag
+	}
+
+	return flags &^ flag
+}
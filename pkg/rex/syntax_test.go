@@ -0,0 +1,261 @@
+package rex_test
+
+import (
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/hedhyw/rex/pkg/dialect"
+	"github.com/hedhyw/rex/pkg/dialect/base"
+	"github.com/hedhyw/rex/pkg/rex"
+)
+
+// nolint: funlen // Test cases.
+func TestRexSyntax(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name     string
+		Tokens   []dialect.Token
+		Expected string
+		Values   []string
+	}{{
+		Name: "literal",
+		Tokens: []dialect.Token{
+			rex.Common.Text("a.b"),
+		},
+		Expected: `a\.b`,
+		Values:   []string{"a.b", "axb", ""},
+	}, {
+		Name: "class",
+		Tokens: []dialect.Token{
+			rex.Chars.Begin(),
+			rex.Common.Class(
+				rex.Chars.Digits(),
+				rex.Chars.Range('a', 'c'),
+				rex.Chars.Single('-'),
+			).Repeat().OneOrMore(),
+			rex.Chars.End(),
+		},
+		Expected: `(?-m:\A[\-0-9a-c]+$)`,
+		Values:   []string{"a-1", "d", "", "ab\n"},
+	}, {
+		Name: "not_class",
+		Tokens: []dialect.Token{
+			rex.Common.NotClass(rex.Chars.Digits()).Repeat().OneOrMore(),
+		},
+		Expected: `[^0-9]+`,
+		Values:   []string{"a\nb", "12", "a1"},
+	}, {
+		Name: "groups",
+		Tokens: []dialect.Token{
+			rex.Group.Define(rex.Common.Text("a")),
+			rex.Group.NonCaptured(rex.Common.Text("b")),
+			rex.Group.Define(rex.Common.Text("c")).WithName("name"),
+			rex.Common.Raw(`(d)`),
+			rex.Group.Define(rex.Common.Text("e")),
+		},
+		Expected: `(a)b(?P<name>c)(d)(e)`,
+		Values:   []string{"abcde", "abcd"},
+	}, {
+		Name: "composite",
+		Tokens: []dialect.Token{
+			rex.Group.Composite(
+				rex.Common.Text("hello"),
+				rex.Chars.Digits().Repeat().Between(2, 3),
+			).NonCaptured(),
+		},
+		Expected: `hello|[0-9]{2,3}`,
+		Values:   []string{"hello", "12", "1", "hell"},
+	}, {
+		Name: "repetitions",
+		Tokens: []dialect.Token{
+			rex.Chars.Single('a').Repeat().ZeroOrMore(),
+			rex.Chars.Single('b').Repeat().OneOrMorePreferFewer(),
+			rex.Chars.Single('c').Repeat().ZeroOrOne(),
+			rex.Chars.Single('d').Repeat().EqualOrMoreThan(2),
+			rex.Chars.Single('e').Repeat().Exactly(2),
+		},
+		Expected: `a*b+?c?d{2,}e{2}`,
+		Values:   []string{"bbdde", "aabbcddee", "dd"},
+	}, {
+		Name: "flags",
+		Tokens: []dialect.Token{
+			rex.Flags.Combine(
+				rex.Flags.CaseInsensitive(),
+				rex.Flags.Multiline(),
+				rex.Flags.AnyIncludeNewLine(),
+			),
+			rex.Chars.Begin(),
+			rex.Common.Text("k"),
+			rex.Chars.Any(),
+			rex.Flags.CaseInsensitive().Disable().Group(rex.Common.Text("b")),
+			rex.Chars.Range('a', 'b'),
+		},
+		Expected: `(?ims:^k.)b[ABab]`,
+		Values:   []string{"k\nbA", "K\nbb", "kxBB", "x\nK\nbb"},
+	}, {
+		Name: "raw_flags",
+		Tokens: []dialect.Token{
+			rex.Common.Raw(`(?i)`),
+			rex.Common.Text("a"),
+			rex.Common.Raw(`[bc]`),
+		},
+		Expected: `(?i:a[BCbc])`,
+		Values:   []string{"A", "AB", "ac"},
+	}, {
+		Name: "ungreedy",
+		Tokens: []dialect.Token{
+			rex.Flags.Ungreedy(),
+			rex.Chars.Any().Repeat().OneOrMore(),
+			rex.Chars.Any().Repeat().ZeroOrOnePreferZero(),
+		},
+		Expected: `(?-s:.+?.?)`,
+		Values:   []string{"abc"},
+	}, {
+		Name: "anchors",
+		Tokens: []dialect.Token{
+			rex.Chars.BeginOfText(),
+			rex.Chars.ASCIIWordBoundary(),
+			rex.Common.Text("a"),
+			rex.Chars.NotASCIIWordBoundary(),
+			rex.Common.Text("b"),
+			rex.Chars.EndOfText(),
+		},
+		Expected: `\A\ba\Bb\z`,
+		Values:   []string{"ab", "a b"},
+	}, {
+		Name:     "empty",
+		Tokens:   []dialect.Token{},
+		Expected: `(?:)`,
+		Values:   []string{"", "a"},
+	}, {
+		Name: "helper",
+		Tokens: []dialect.Token{
+			rex.Chars.Begin(),
+			rex.Helper.IPv4(),
+			rex.Chars.End(),
+		},
+		Values: []string{"127.0.0.1", "256.0.0.1", "1.1.1"},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			rexRe := rex.New(tc.Tokens...)
+
+			syntaxRe, err := rexRe.Syntax(syntax.Perl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if actual := syntaxRe.String(); tc.Expected != "" && actual != tc.Expected {
+				t.Fatalf("Actual: %#q, Expected: %#q", actual, tc.Expected)
+			}
+
+			expectedRe := rexRe.MustCompile()
+			actualRe := regexp.MustCompile(syntaxRe.String())
+
+			if actualRe.NumSubexp() != expectedRe.NumSubexp() {
+				t.Fatalf("Actual: %d, Expected: %d", actualRe.NumSubexp(), expectedRe.NumSubexp())
+			}
+
+			for _, val := range tc.Values {
+				actual := actualRe.FindStringSubmatch(val)
+				expected := expectedRe.FindStringSubmatch(val)
+
+				if len(actual) != len(expected) {
+					t.Fatalf("%q: Actual: %q, Expected: %q", val, actual, expected)
+				}
+
+				for i := range actual {
+					if actual[i] != expected[i] {
+						t.Fatalf("%q: Actual: %q, Expected: %q", val, actual, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRexSyntaxCompile(t *testing.T) {
+	t.Parallel()
+
+	re, err := rex.New(
+		rex.Group.Define(rex.Chars.Digits()).WithName("first"),
+		rex.Group.Define(rex.Chars.Digits()).WithName("second"),
+	).Syntax(syntax.Perl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if re.MaxCap() != 2 {
+		t.Fatalf("Actual: %d, Expected: %d", re.MaxCap(), 2)
+	}
+
+	names := re.CapNames()
+	if names[1] != "first" || names[2] != "second" {
+		t.Fatalf("Actual: %q", names)
+	}
+
+	if _, err := syntax.Compile(re.Simplify()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRexSyntaxPOSIX(t *testing.T) {
+	t.Parallel()
+
+	re, err := rex.New(
+		rex.Common.NotClass(rex.Chars.Single('a')),
+		rex.Chars.Begin(),
+	).Syntax(syntax.POSIX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `(?m:[^\na]^)`
+
+	if actual := re.String(); actual != expected {
+		t.Fatalf("Actual: %#q, Expected: %#q", actual, expected)
+	}
+}
+
+func TestRexSyntaxFailed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("token", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rex.New(rex.Chars.Range('b', 'a')).Syntax(syntax.Perl)
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := rex.New(rex.Common.Raw(`[a-`)).Syntax(syntax.Perl)
+		if err == nil {
+			t.Fatal("Expected error")
+		}
+	})
+
+	t.Run("repetition", func(t *testing.T) {
+		t.Parallel()
+
+		// Nested repetitions exceed the limit of RE2, they are reported
+		// by the builder.
+		_, err := rex.New(
+			rex.Group.Define(rex.Chars.Digits().Repeat().Exactly(100)).Repeat().Exactly(100),
+		).Syntax(syntax.Perl)
+		if !errors.Is(err, base.ErrInvalidRepetition) {
+			t.Fatalf("Actual: %v, Expected: %v", err, base.ErrInvalidRepetition)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package rex
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// tagName is a name of the struct tag for Unmarshal.
+	tagName = "rex"
+	// layoutOption is an option of the tag that specifies time layout.
+	layoutOption = "layout="
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldTag returns the name of the group and options of the field. It
+// returns false if the field is not bound to a group.
+func fieldTag(field reflect.StructField) (name string, options string, ok bool) {
+	tag, ok := field.Tag.Lookup(tagName)
+	if !ok || tag == "-" || !field.IsExported() {
+		return "", "", false
+	}
+
+	name, options, _ = strings.Cut(tag, ",")
+
+	return name, options, true
+}
+
+// checkTags verifies that all tags of the struct refer to named groups,
+// so typos are not ignored.
+func checkTags(targetType reflect.Type, groupNames []string) error {
+	known := make(map[string]struct{}, len(groupNames))
+
+	for _, name := range groupNames {
+		known[name] = struct{}{}
+	}
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+
+		name, _, ok := fieldTag(field)
+		if !ok {
+			continue
+		}
+
+		if _, ok := known[name]; !ok || name == "" {
+			return fmt.Errorf("%w: field %s: unknown group %q", ErrInvalidUnmarshal, field.Name, name)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalStruct sets fields of the struct from values by tags.
+func unmarshalStruct(target reflect.Value, values map[string]string) error {
+	targetType := target.Type()
+
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+
+		name, options, ok := fieldTag(field)
+		if !ok {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		layout := time.RFC3339
+		if strings.HasPrefix(options, layoutOption) {
+			layout = strings.TrimPrefix(options, layoutOption)
+		}
+
+		if err := setValue(target.Field(i), value, layout); err != nil {
+			return fmt.Errorf("field %s (group %q): %w", field.Name, name, err)
+		}
+	}
+
+	return nil
+}
+
+// setValue converts the text to the type of the target and sets it.
+//
+// nolint: cyclop // One case per a kind.
+func setValue(target reflect.Value, value string, layout string) error {
+	if target.Kind() == reflect.Pointer {
+		ptr := reflect.New(target.Type().Elem())
+
+		if err := setValue(ptr.Elem(), value, layout); err != nil {
+			return err
+		}
+
+		target.Set(ptr)
+
+		return nil
+	}
+
+	switch target.Type() {
+	case timeType:
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+
+		target.Set(reflect.ValueOf(t))
+
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(int64(d))
+
+		return nil
+	}
+
+	if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+		unmarshaler, _ := target.Addr().Interface().(encoding.TextUnmarshaler)
+
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: unsupported type %s", ErrInvalidUnmarshal, target.Type())
+	}
+
+	return nil
+}